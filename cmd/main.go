@@ -5,8 +5,12 @@ import (
 	"Cars/internal/migrations"
 	"Cars/internal/routes"
 	"Cars/internal/services"
+	"context"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -28,9 +32,9 @@ func main() {
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:4200"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
@@ -39,21 +43,79 @@ func main() {
 	profileService := services.NewProfileService(services.DB)
 	superAdminService := services.NewSuperAdminService(services.DB)
 	favoriteService := services.NewFavoriteService(services.DB)
+	reservationService := services.NewReservationService(services.DB, reservationHold())
+	carImageService := services.NewCarImageService(services.DB)
+	notificationService := services.NewNotificationService(services.DB)
+	compareService := services.NewCarCompareService(services.DB, reviewService, maxCompareCars())
+	similarCarsService, err := services.NewSimilarCarsService(services.DB, similarityWeights())
+	if err != nil {
+		log.Fatal(err)
+	}
+	recommendationService := services.NewRecommendationService(services.DB, reviewService)
+	carReferenceService := services.NewCarReferenceService(services.DB)
+	carTrashService := services.NewCarTrashService(services.DB, trashRetention())
+	testDriveService := services.NewTestDriveService(services.DB, testDriveLocation())
+	messageService := services.NewMessageService(services.DB)
+	offerService := services.NewOfferService(services.DB, reservationService, services.DefaultOfferTTL)
+	orderService := services.NewOrderService(services.DB)
+	paymentService := services.NewPaymentService(services.DB, paymentProvider())
+	financingService := services.NewFinancingService(services.DB)
+	exchangeRateService := services.NewExchangeRateService(services.DB)
 
 	// Initialize controllers
 	reviewController := controllers.NewReviewController(reviewService)
 	profileController := controllers.NewProfileController(profileService)
 	superAdminController := controllers.NewSuperAdminController(superAdminService)
 	favoriteController := controllers.NewFavoriteController(favoriteService)
+	reservationController := controllers.NewReservationController(reservationService)
+	carImageController := controllers.NewCarImageController(carImageService)
+	notificationController := controllers.NewNotificationController(notificationService)
+	comparisonController := controllers.NewComparisonController(compareService)
+	similarCarsController := controllers.NewSimilarCarsController(similarCarsService)
+	recommendationController := controllers.NewRecommendationController(recommendationService)
+	carReferenceController := controllers.NewCarReferenceController(carReferenceService)
+	carTrashController := controllers.NewCarTrashController(carTrashService)
+	testDriveController := controllers.NewTestDriveController(testDriveService)
+	messageController := controllers.NewMessageController(messageService)
+	offerController := controllers.NewOfferController(offerService)
+	orderController := controllers.NewOrderController(orderService)
+	paymentController := controllers.NewPaymentController(paymentService)
+	financingController := controllers.NewFinancingController(financingService)
+	exchangeRateController := controllers.NewExchangeRateController(exchangeRateService)
 
 	// Маршруттарды тіркеу
 	controllers.RegisterAuthRoutes(router)
 	controllers.RegisterCarRoutes(router)
+	controllers.RegisterMyListingRoutes(router)
 	controllers.RegisterUserRoutes(router)
 	routes.SetupReviewRoutes(router, reviewController)
 	controllers.RegisterProfileRoutes(router, profileController)
 	controllers.RegisterSuperAdminRoutes(router, superAdminController)
 	routes.SetupFavoriteRoutes(router, favoriteController)
+	routes.SetupReservationRoutes(router, reservationController)
+	routes.SetupCarImageRoutes(router, carImageController)
+	routes.SetupNotificationRoutes(router, notificationController)
+	routes.SetupComparisonRoutes(router, comparisonController)
+	routes.SetupSimilarCarRoutes(router, similarCarsController)
+	routes.SetupRecommendationRoutes(router, recommendationController)
+	routes.SetupCarReferenceRoutes(router, carReferenceController)
+	routes.SetupVINRoutes(router)
+	routes.SetupCarTrashRoutes(router, carTrashController)
+	routes.SetupTestDriveRoutes(router, testDriveController)
+	routes.SetupMessageRoutes(router, messageController)
+	routes.SetupOfferRoutes(router, offerController)
+	routes.SetupOrderRoutes(router, orderController)
+	routes.SetupPaymentRoutes(router, paymentController)
+	routes.SetupFinancingRoutes(router, financingController)
+	routes.SetupExchangeRateRoutes(router, exchangeRateController)
+
+	// Загруженные файлы
+	router.Static(services.UploadsURLPrefix, services.UploadsDir)
+
+	// Фоновые задачи
+	go reservationService.StartExpiryWorker(context.Background(), time.Minute)
+	go carTrashService.StartPurgeWorker(context.Background(), time.Hour)
+	go offerService.StartExpiryWorker(context.Background(), time.Minute)
 
 	//серверді іске қосамыз
 	server := &http.Server{
@@ -66,3 +128,96 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// reservationHold reads how many hours a reservation holds a car from RESERVATION_HOLD_HOURS
+func reservationHold() time.Duration {
+	raw := os.Getenv("RESERVATION_HOLD_HOURS")
+	if raw == "" {
+		return services.DefaultReservationHold
+	}
+	hours, err := strconv.Atoi(raw)
+	if err != nil || hours < 1 {
+		log.Fatalf("RESERVATION_HOLD_HOURS must be a positive number of hours, got %q", raw)
+	}
+	return time.Duration(hours) * time.Hour
+}
+
+// maxCompareCars reads how many cars can be compared at once from MAX_COMPARE_CARS
+func maxCompareCars() int {
+	raw := os.Getenv("MAX_COMPARE_CARS")
+	if raw == "" {
+		return services.DefaultMaxCompareCars
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 2 {
+		log.Fatalf("MAX_COMPARE_CARS must be a number of at least 2, got %q", raw)
+	}
+	return n
+}
+
+// similarityWeights reads the weights of similar car scoring from SIMILARITY_WEIGHTS, e.g. "price=4,brand=1"
+func similarityWeights() services.SimilarityWeights {
+	weights, err := services.ParseSimilarityWeights(os.Getenv("SIMILARITY_WEIGHTS"))
+	if err != nil {
+		log.Fatalf("SIMILARITY_WEIGHTS: %v", err)
+	}
+	return weights
+}
+
+// trashRetention reads how many days deleted cars are kept from CAR_TRASH_RETENTION_DAYS
+func trashRetention() time.Duration {
+	raw := os.Getenv("CAR_TRASH_RETENTION_DAYS")
+	if raw == "" {
+		return services.DefaultTrashRetention
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 {
+		log.Fatalf("CAR_TRASH_RETENTION_DAYS must be a positive number of days, got %q", raw)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// testDriveLocation reads the time zone of test drive schedules from TEST_DRIVE_TIMEZONE, e.g. Asia/Almaty
+func testDriveLocation() *time.Location {
+	name := os.Getenv("TEST_DRIVE_TIMEZONE")
+	if name == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Fatalf("TEST_DRIVE_TIMEZONE must be an IANA time zone, got %q", name)
+	}
+	return loc
+}
+
+// paymentProvider builds the payment gateway selected by PAYMENT_PROVIDER.
+// Only the in-process fake provider is available; its webhooks are signed with PAYMENT_WEBHOOK_SECRET
+// and sent to PAYMENT_WEBHOOK_URL after FAKE_PAYMENT_DELAY_SECONDS.
+func paymentProvider() services.PaymentProvider {
+	name := os.Getenv("PAYMENT_PROVIDER")
+	if name != "" && name != services.FakePaymentProviderName {
+		log.Fatalf("PAYMENT_PROVIDER must be %q, got %q", services.FakePaymentProviderName, name)
+	}
+
+	secret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if secret == "" {
+		secret = "dev-webhook-secret"
+		log.Println("PAYMENT_WEBHOOK_SECRET is not set, using the development secret")
+	}
+
+	webhookURL := os.Getenv("PAYMENT_WEBHOOK_URL")
+	if webhookURL == "" {
+		webhookURL = "http://localhost:8081/api/payments/webhooks/" + services.FakePaymentProviderName
+	}
+
+	delay := 5 * time.Second
+	if raw := os.Getenv("FAKE_PAYMENT_DELAY_SECONDS"); raw != "" {
+		seconds, err := strconv.Atoi(raw)
+		if err != nil || seconds < 0 {
+			log.Fatalf("FAKE_PAYMENT_DELAY_SECONDS must be a non-negative number of seconds, got %q", raw)
+		}
+		delay = time.Duration(seconds) * time.Second
+	}
+
+	return services.NewFakePaymentProvider(secret, delay, webhookURL)
+}
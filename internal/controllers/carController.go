@@ -1,7 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"Cars/internal/middleware"
 	"Cars/internal/models"
@@ -20,22 +27,75 @@ import (
 
 func RegisterCarRoutes(router *gin.Engine) {
 	router.GET("/cars", getCars)
+	router.GET("/cars/search", searchCars)
 	router.GET("/cars/:id", middleware.AuthMiddleware(), GetCarByID)
+	router.GET("/cars/:id/price-history", getCarPriceHistory)
 
 	carGroup := router.Group("/cars")
 	carGroup.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
 
 	{
 		carGroup.POST("", createCar)
-		carGroup.PUT("/:id", updateCar)
-		carGroup.DELETE("/:id", deleteCar)
+		carGroup.POST("/import", importCars)
+		carGroup.GET("/export", exportCars)
+		carGroup.POST("/prices/bulk", bulkUpdatePrices)
+		carGroup.PUT("/:id", middleware.RequireIfMatch(), updateCar)
+		carGroup.PATCH("/:id", middleware.RequireIfMatch(), patchCar)
+		carGroup.DELETE("/:id", middleware.RequireIfMatch(), deleteCar)
+		carGroup.POST("/:id/status", changeCarStatus)
+		carGroup.GET("/:id/status-history", getCarStatusHistory)
 	}
 }
 
-// Барлық көліктерді алу
+// Көліктерді сүзу, сұрыптау және беттеу арқылы алу
 func getCars(c *gin.Context) {
-	cars := services.GetCars()
-	c.JSON(http.StatusOK, cars)
+	query, err := services.ParseCarQuery(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	query.Currency = currency
+
+	cars, total, err := services.ListCars(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPaginatedResponse(c, cars, total, query.Page, query.PerPage))
+}
+
+// Көліктерді мәтін бойынша іздеу
+func searchCars(c *gin.Context) {
+	page, perPage, err := services.ParsePage(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+
+	cars, total, err := services.SearchCars(c.Query("q"), page, perPage)
+	if err != nil {
+		if errors.Is(err, services.ErrEmptySearchQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ConvertCarPrices(cars, currency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPaginatedResponse(c, cars, total, page, perPage))
 }
 
 // Көлік қосу
@@ -45,38 +105,347 @@ func createCar(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	services.CreateCar(car)
+	// Машины, добавленные администратором, принадлежат автосалону
+	car.OwnerID = nil
+	if err := services.CreateCar(&car, c.GetUint("userID")); err != nil {
+		c.JSON(carWriteError(err), gin.H{"error": err.Error()})
+		return
+	}
 	c.JSON(http.StatusCreated, car)
 }
 
-// Көлікті жаңарту
+// MaxImportBodySize limits the size of an import upload
+const MaxImportBodySize = 10 << 20
+
+// Көліктерді CSV немесе JSON файлынан жаппай қосу
+func importCars(c *gin.Context) {
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+		dryRun = v
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImportBodySize)
+
+	var body io.Reader = c.Request.Body
+	format := c.ContentType()
+	if format == "multipart/form-data" {
+		fh, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "multipart import requires a file field"})
+			return
+		}
+		file, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		defer file.Close()
+		body = file
+
+		format = fh.Header.Get("Content-Type")
+		if strings.HasSuffix(strings.ToLower(fh.Filename), ".csv") {
+			format = "text/csv"
+		} else if strings.HasSuffix(strings.ToLower(fh.Filename), ".json") {
+			format = "application/json"
+		}
+	}
+
+	var rows []services.CarImportRow
+	var err error
+	switch format {
+	case "text/csv", "application/csv":
+		rows, err = services.ParseCarCSV(body)
+	case "application/json":
+		rows, err = services.ParseCarJSON(body)
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "import accepts text/csv or application/json"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := services.ImportCars(rows, services.CarImportOptions{
+		Mode:    c.DefaultQuery("mode", services.ImportAtomic),
+		DryRun:  dryRun,
+		ActorID: c.GetUint("userID"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidImportMode) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if report.Committed {
+		status = http.StatusCreated
+	} else if report.Rejected > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, report)
+}
+
+// Каталогты CSV, JSON Lines немесе XLSX түрінде экспорттау
+func exportCars(c *gin.Context) {
+	values := c.Request.URL.Query()
+	format := strings.ToLower(values.Get("format"))
+	if format == "" {
+		format = services.ExportCSV
+	}
+	values.Del("format")
+
+	contentType, ok := services.ExportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidExportFormat.Error()})
+		return
+	}
+
+	query, err := services.ParseCarQuery(values)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Экспорт хранит цены как есть, валюта влияет только на фильтры price_min/price_max
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+	query.Currency = currency
+
+	filename := fmt.Sprintf("cars-%s.%s", time.Now().Format("20060102-150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	if err := services.ExportCars(query, format, c.Writer); err != nil {
+		if !c.Writer.Written() {
+			// Ошибка уходит JSON-ом, а не файлом: заголовки файла снимаем
+			c.Header("Content-Type", "")
+			c.Header("Content-Disposition", "")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		// Заголовки уже отправлены, остаётся только оборвать поток
+		log.Printf("car export failed: %v", err)
+		c.Abort()
+	}
+}
+
+// Көлікті толық ауыстыру: жіберілмеген өрістер тазаланады
 func updateCar(c *gin.Context) {
 	id := c.Param("id")
+	match, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	var car models.Car
 	if err := c.ShouldBindJSON(&car); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	services.UpdateCar(id, car)
-	c.JSON(http.StatusOK, car)
+	updated, err := services.ReplaceCar(id, car, carEditor(c), match)
+	if err != nil {
+		c.JSON(carWriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, updated)
+}
+
+// Көлікті JSON Merge Patch (RFC 7396) арқылы ішінара жаңарту
+func patchCar(c *gin.Context) {
+	contentType := c.ContentType()
+	if contentType != "application/merge-patch+json" && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/merge-patch+json"})
+		return
+	}
+	match, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	updated, err := services.PatchCar(c.Param("id"), patch, carEditor(c), match)
+	if err != nil {
+		c.JSON(carWriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+	setETag(c, updated.Version)
+	c.JSON(http.StatusOK, updated)
 }
 
 // Көлікті өшіру
 func deleteCar(c *gin.Context) {
 	id := c.Param("id")
-	if err := services.DeleteCar(id); err != nil {
+	match, err := ifMatch(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.DeleteCar(id, carEditor(c), match); err != nil {
+		c.JSON(carWriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Car moved to trash"})
+}
+
+// ChangeCarStatusRequest is the body of POST /cars/:id/status
+type ChangeCarStatusRequest struct {
+	Status models.CarStatus `json:"status" binding:"required"`
+	Reason string           `json:"reason"`
+}
+
+// Көлік статусын өзгерту
+func changeCarStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req ChangeCarStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Car deleted successfully"})
+
+	userID := c.GetUint("userID")
+	car, err := services.ChangeCarStatus(uint(id), req.Status, &userID, req.Reason, true)
+	if err != nil {
+		c.JSON(carStatusError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	setETag(c, car.Version)
+	c.JSON(http.StatusOK, car)
+}
+
+// Көлік статусының тарихын алу
+func getCarStatusHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	history, err := services.GetCarStatusHistory(uint(id))
+	if err != nil {
+		c.JSON(carStatusError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Көлік бағасының тарихын алу
+func getCarPriceHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	history, err := services.GetCarPriceHistory(uint(id))
+	if err != nil {
+		c.JSON(carStatusError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// Бірнеше көліктің бағасын бір уақытта өзгерту
+func bulkUpdatePrices(c *gin.Context) {
+	var req services.BulkPriceUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cars, err := services.BulkUpdatePrices(req, c.GetUint("userID"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidPriceUpdate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(carStatusError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": len(cars), "data": cars})
+}
+
+// carStatusError maps status lifecycle errors to HTTP status codes
+func carStatusError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidCarStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, models.ErrIllegalStatusTransition), errors.Is(err, services.ErrCarHasOpenOrder):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// carWriteError maps errors of car create and update to HTTP status codes
+func carWriteError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidCar):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrUnknownCarReference), errors.Is(err, services.ErrVINMismatch),
+		errors.Is(err, services.ErrUnsupportedCurrency):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrDuplicateVIN), errors.Is(err, services.ErrCarHasOpenOrder):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrVersionMismatch):
+		return http.StatusPreconditionFailed
+	case errors.Is(err, services.ErrCarForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// carEditor describes the caller for car writes; everyone but admins may only change their own listings
+func carEditor(c *gin.Context) services.CarEditor {
+	return services.CarEditor{UserID: c.GetUint("userID"), OwnerOnly: !isAdmin(c)}
 }
 
 func GetCarByID(c *gin.Context) {
+	currency, ok := requestedCurrency(c)
+	if !ok {
+		return
+	}
+
 	id := c.Param("id")
 	car, err := services.GetCarByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Car not found"})
 		return
 	}
+	if err := services.ConvertCarPrice(car, currency); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setETag(c, car.Version)
 	c.JSON(http.StatusOK, car)
 }
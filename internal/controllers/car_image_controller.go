@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CarImageController struct {
+	carImageService *services.CarImageService
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" binding:"required"`
+}
+
+func NewCarImageController(carImageService *services.CarImageService) *CarImageController {
+	return &CarImageController{
+		carImageService: carImageService,
+	}
+}
+
+// UploadImages handles POST /cars/:id/images (multipart field "images")
+func (c *CarImageController) UploadImages(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	// Ограничиваем весь запрос, чтобы не принимать гигантские тела
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, services.MaxCarImageSize*services.MaxCarImagesPerUpload+(1<<20))
+	form, err := ctx.MultipartForm()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid multipart form: " + err.Error()})
+		return
+	}
+
+	images, err := c.carImageService.UploadImages(uint(carID), form.File["images"])
+	if err != nil {
+		ctx.JSON(carImageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, images)
+}
+
+// GetCarImages handles GET /cars/:id/images
+func (c *CarImageController) GetCarImages(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	images, err := c.carImageService.GetCarImages(uint(carID))
+	if err != nil {
+		ctx.JSON(carImageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, images)
+}
+
+// DeleteImage handles DELETE /cars/:id/images/:image_id
+func (c *CarImageController) DeleteImage(ctx *gin.Context) {
+	carID, imageID, ok := parseCarImageIDs(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.carImageService.DeleteImage(carID, imageID); err != nil {
+		ctx.JSON(carImageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// SetPrimaryImage handles PUT /cars/:id/images/:image_id/primary
+func (c *CarImageController) SetPrimaryImage(ctx *gin.Context) {
+	carID, imageID, ok := parseCarImageIDs(ctx)
+	if !ok {
+		return
+	}
+
+	images, err := c.carImageService.SetPrimaryImage(carID, imageID)
+	if err != nil {
+		ctx.JSON(carImageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, images)
+}
+
+// ReorderImages handles PUT /cars/:id/images/order
+func (c *CarImageController) ReorderImages(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req ReorderImagesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	images, err := c.carImageService.ReorderImages(uint(carID), req.ImageIDs)
+	if err != nil {
+		ctx.JSON(carImageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, images)
+}
+
+// parseCarImageIDs reads the car and image IDs from the path, writing a 400 on failure
+func parseCarImageIDs(ctx *gin.Context) (uint, uint, bool) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return 0, 0, false
+	}
+	imageID, err := strconv.ParseUint(ctx.Param("image_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid image ID"})
+		return 0, 0, false
+	}
+	return uint(carID), uint(imageID), true
+}
+
+// carImageError maps gallery errors to HTTP status codes
+func carImageError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCarNotFound), errors.Is(err, services.ErrCarImageNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrImageTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, services.ErrUnsupportedImageType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, services.ErrNoImagesUploaded), errors.Is(err, services.ErrTooManyImages),
+		errors.Is(err, services.ErrInvalidImage), errors.Is(err, services.ErrInvalidImageOrder):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CarReferenceController struct {
+	referenceService *services.CarReferenceService
+}
+
+func NewCarReferenceController(referenceService *services.CarReferenceService) *CarReferenceController {
+	return &CarReferenceController{
+		referenceService: referenceService,
+	}
+}
+
+// ReferenceRequest is the body of the create and update reference endpoints
+type ReferenceRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ListBrands handles GET /brands
+func (c *CarReferenceController) ListBrands(ctx *gin.Context) {
+	brands, err := c.referenceService.ListBrands()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, brands)
+}
+
+// CreateBrand handles POST /brands
+func (c *CarReferenceController) CreateBrand(ctx *gin.Context) {
+	var req ReferenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	brand, err := c.referenceService.CreateBrand(req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, brand)
+}
+
+// UpdateBrand handles PUT /brands/:id
+func (c *CarReferenceController) UpdateBrand(ctx *gin.Context) {
+	id, req, ok := bindReferenceUpdate(ctx)
+	if !ok {
+		return
+	}
+
+	brand, err := c.referenceService.UpdateBrand(id, req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, brand)
+}
+
+// DeleteBrand handles DELETE /brands/:id
+func (c *CarReferenceController) DeleteBrand(ctx *gin.Context) {
+	id, ok := referenceID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.referenceService.DeleteBrand(id); err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "brand deleted"})
+}
+
+// ListModels handles GET /brands/:id/models and GET /car-models
+func (c *CarReferenceController) ListModels(ctx *gin.Context) {
+	var brandID uint64
+	raw := ctx.Param("id")
+	if raw == "" {
+		raw = ctx.Query("brand_id")
+	}
+	if raw != "" {
+		var err error
+		brandID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid brand ID"})
+			return
+		}
+	}
+
+	carModels, err := c.referenceService.ListModels(uint(brandID))
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, carModels)
+}
+
+// CreateModel handles POST /brands/:id/models
+func (c *CarReferenceController) CreateModel(ctx *gin.Context) {
+	brandID, req, ok := bindReferenceUpdate(ctx)
+	if !ok {
+		return
+	}
+
+	carModel, err := c.referenceService.CreateModel(brandID, req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, carModel)
+}
+
+// UpdateModel handles PUT /car-models/:id
+func (c *CarReferenceController) UpdateModel(ctx *gin.Context) {
+	id, req, ok := bindReferenceUpdate(ctx)
+	if !ok {
+		return
+	}
+
+	carModel, err := c.referenceService.UpdateModel(id, req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, carModel)
+}
+
+// DeleteModel handles DELETE /car-models/:id
+func (c *CarReferenceController) DeleteModel(ctx *gin.Context) {
+	id, ok := referenceID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.referenceService.DeleteModel(id); err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "model deleted"})
+}
+
+// ListCarTypes handles GET /car-types
+func (c *CarReferenceController) ListCarTypes(ctx *gin.Context) {
+	types, err := c.referenceService.ListCarTypes()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, types)
+}
+
+// CreateCarType handles POST /car-types
+func (c *CarReferenceController) CreateCarType(ctx *gin.Context) {
+	var req ReferenceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	carType, err := c.referenceService.CreateCarType(req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, carType)
+}
+
+// UpdateCarType handles PUT /car-types/:id
+func (c *CarReferenceController) UpdateCarType(ctx *gin.Context) {
+	id, req, ok := bindReferenceUpdate(ctx)
+	if !ok {
+		return
+	}
+
+	carType, err := c.referenceService.UpdateCarType(id, req.Name)
+	if err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, carType)
+}
+
+// DeleteCarType handles DELETE /car-types/:id
+func (c *CarReferenceController) DeleteCarType(ctx *gin.Context) {
+	id, ok := referenceID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.referenceService.DeleteCarType(id); err != nil {
+		ctx.JSON(referenceError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "car type deleted"})
+}
+
+// referenceID reads the :id path parameter and writes a 400 response if it is invalid
+func referenceID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// bindReferenceUpdate reads the :id path parameter and the request body
+func bindReferenceUpdate(ctx *gin.Context) (uint, ReferenceRequest, bool) {
+	var req ReferenceRequest
+	id, ok := referenceID(ctx)
+	if !ok {
+		return 0, req, false
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return 0, req, false
+	}
+	return id, req, true
+}
+
+// referenceError maps reference catalog errors to HTTP status codes
+func referenceError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReferenceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidReference):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrReferenceExists), errors.Is(err, services.ErrReferenceInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
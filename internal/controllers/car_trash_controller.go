@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CarTrashController struct {
+	trashService *services.CarTrashService
+}
+
+func NewCarTrashController(trashService *services.CarTrashService) *CarTrashController {
+	return &CarTrashController{
+		trashService: trashService,
+	}
+}
+
+// ListTrash handles GET /admin/cars/trash?page=1&per_page=20
+func (c *CarTrashController) ListTrash(ctx *gin.Context) {
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cars, total, err := c.trashService.ListTrash(page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, cars, total, page, perPage))
+}
+
+// RestoreCar handles POST /cars/:id/restore
+func (c *CarTrashController) RestoreCar(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	car, err := c.trashService.RestoreCar(uint(carID))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrCarNotFound):
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrCarNotDeleted), errors.Is(err, services.ErrDuplicateVIN):
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, car)
+}
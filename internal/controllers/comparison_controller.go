@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ComparisonController struct {
+	compareService *services.CarCompareService
+}
+
+func NewComparisonController(compareService *services.CarCompareService) *ComparisonController {
+	return &ComparisonController{
+		compareService: compareService,
+	}
+}
+
+// SaveComparisonRequest is the body of POST /api/comparisons
+type SaveComparisonRequest struct {
+	Name   string `json:"name"`
+	CarIDs []uint `json:"car_ids" binding:"required"`
+}
+
+// CompareCars handles GET /cars/compare?ids=1,2,3
+func (c *ComparisonController) CompareCars(ctx *gin.Context) {
+	ids, err := c.compareService.ParseCompareIDs(ctx.Query("ids"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comparison, err := c.compareService.CompareCars(ids)
+	if err != nil {
+		ctx.JSON(comparisonError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comparison)
+}
+
+// SaveComparison handles POST /api/comparisons
+func (c *ComparisonController) SaveComparison(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req SaveComparisonRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	saved, err := c.compareService.SaveComparison(userID, req.Name, req.CarIDs)
+	if err != nil {
+		ctx.JSON(comparisonError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, saved)
+}
+
+// GetSavedComparisons handles GET /api/comparisons
+func (c *ComparisonController) GetSavedComparisons(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	saved, err := c.compareService.GetSavedComparisons(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
+
+// OpenSavedComparison handles GET /api/comparisons/:id
+func (c *ComparisonController) OpenSavedComparison(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comparison ID"})
+		return
+	}
+
+	saved, comparison, err := c.compareService.OpenSavedComparison(userID, uint(id))
+	if err != nil {
+		ctx.JSON(comparisonError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"saved": saved, "comparison": comparison})
+}
+
+// DeleteSavedComparison handles DELETE /api/comparisons/:id
+func (c *ComparisonController) DeleteSavedComparison(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid comparison ID"})
+		return
+	}
+
+	if err := c.compareService.DeleteSavedComparison(userID, uint(id)); err != nil {
+		ctx.JSON(comparisonError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "comparison deleted"})
+}
+
+// comparisonError maps comparison errors to HTTP status codes
+func comparisonError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCarNotFound), errors.Is(err, services.ErrComparisonNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidComparison):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrTooManyComparisons):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,26 @@
+package controllers
+
+import (
+	"Cars/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// currentUser returns the authenticated user set by AuthMiddleware
+func currentUser(ctx *gin.Context) *models.User {
+	user, exists := ctx.Get("user")
+	if !exists {
+		return nil
+	}
+	userObj, _ := user.(*models.User)
+	return userObj
+}
+
+// isAdmin reports whether the authenticated user is an ADMIN or SUPER_ADMIN
+func isAdmin(ctx *gin.Context) bool {
+	user := currentUser(ctx)
+	if user == nil {
+		return false
+	}
+	return user.Role == string(models.RoleAdmin) || user.Role == string(models.RoleSuperAdmin)
+}
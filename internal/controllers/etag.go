@@ -0,0 +1,55 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidIfMatch is returned for an If-Match header that is not a list of entity tags
+var errInvalidIfMatch = errors.New(`If-Match must be "*" or a list of entity tags such as "3"`)
+
+// etag formats a resource version as a strong entity tag
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sends the version of the returned resource
+func setETag(ctx *gin.Context, version int) {
+	ctx.Header("ETag", etag(version))
+}
+
+// ifMatch parses the If-Match header into the versions a write may overwrite.
+// No header and "*" accept any version. Weak tags never match because If-Match uses strong comparison.
+func ifMatch(ctx *gin.Context) (services.VersionMatch, error) {
+	header := strings.TrimSpace(ctx.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	var match services.VersionMatch
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") {
+			continue
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			return nil, errInvalidIfMatch
+		}
+		version, err := strconv.Atoi(tag[1 : len(tag)-1])
+		if err != nil {
+			// Чужой тег не совпадёт ни с одной версией
+			version = 0
+		}
+		match = append(match, version)
+	}
+	if len(match) == 0 {
+		// Версии начинаются с 1, так что 0 не совпадёт ни с чем
+		match = services.VersionMatch{0}
+	}
+	return match, nil
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AcceptCurrencyHeader selects the currency of converted prices when the currency parameter is not given
+const AcceptCurrencyHeader = "Accept-Currency"
+
+type ExchangeRateController struct {
+	exchangeRateService *services.ExchangeRateService
+}
+
+func NewExchangeRateController(exchangeRateService *services.ExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// ExchangeRateRequest is the body of PUT /exchange-rates/:currency
+type ExchangeRateRequest struct {
+	Rate     float64 `json:"rate" binding:"required"`
+	Decimals *int    `json:"decimals"`
+}
+
+// ListRates handles GET /exchange-rates
+func (c *ExchangeRateController) ListRates(ctx *gin.Context) {
+	rates, err := c.exchangeRateService.ListRates()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"base_currency": models.BaseCurrency, "rates": rates})
+}
+
+// SetRate handles PUT /exchange-rates/:currency and creates the currency if it is new
+func (c *ExchangeRateController) SetRate(ctx *gin.Context) {
+	var req ExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rate, err := c.exchangeRateService.SetRate(ctx.Param("currency"), req.Rate, req.Decimals, ctx.GetUint("userID"))
+	if err != nil {
+		ctx.JSON(exchangeRateError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, rate)
+}
+
+// DeleteRate handles DELETE /exchange-rates/:currency
+func (c *ExchangeRateController) DeleteRate(ctx *gin.Context) {
+	if err := c.exchangeRateService.DeleteRate(ctx.Param("currency")); err != nil {
+		ctx.JSON(exchangeRateError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "exchange rate deleted"})
+}
+
+// requestedCurrency resolves the currency from the currency parameter or the Accept-Currency header
+// and writes the error response itself. A nil rate means prices are returned unconverted.
+func requestedCurrency(ctx *gin.Context) (*models.ExchangeRate, bool) {
+	code := ctx.Query("currency")
+	if code == "" {
+		code = ctx.GetHeader(AcceptCurrencyHeader)
+	}
+	ctx.Header("Vary", AcceptCurrencyHeader)
+
+	rate, err := services.ResolveCurrency(code)
+	if err != nil {
+		ctx.JSON(exchangeRateError(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return rate, true
+}
+
+// exchangeRateError maps exchange rate errors to HTTP status codes
+func exchangeRateError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrExchangeRateNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidCurrency), errors.Is(err, models.ErrInvalidExchangeRate),
+		errors.Is(err, services.ErrUnsupportedCurrency), errors.Is(err, services.ErrBaseCurrencyRate):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCurrencyInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"Cars/internal/models"
 	"Cars/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -35,6 +37,10 @@ func (c *FavoriteController) AddToFavorites(ctx *gin.Context) {
 
 	favorite, err := c.favoriteService.AddToFavorites(userID, uint(carID))
 	if err != nil {
+		if errors.Is(err, services.ErrCarNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -98,3 +104,89 @@ func (c *FavoriteController) IsFavorite(ctx *gin.Context) {
 	isFavorite := c.favoriteService.IsFavorite(userID, uint(carID))
 	ctx.JSON(http.StatusOK, gin.H{"is_favorite": isFavorite})
 }
+
+// PriceAlertRequest is the body of PUT /api/favorites/:car_id/alert
+type PriceAlertRequest struct {
+	AlertType   models.PriceAlertType `json:"alert_type" binding:"required"`
+	TargetPrice *float64              `json:"target_price"`
+}
+
+// SetPriceAlert обработчик для настройки уведомления о снижении цены
+func (c *FavoriteController) SetPriceAlert(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("car_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req PriceAlertRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	favorite, err := c.favoriteService.SetPriceAlert(userID, uint(carID), req.AlertType, req.TargetPrice)
+	if err != nil {
+		ctx.JSON(favoriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, favorite)
+}
+
+// RemovePriceAlert обработчик для отключения уведомления о снижении цены
+func (c *FavoriteController) RemovePriceAlert(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("car_id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	if err := c.favoriteService.RemovePriceAlert(userID, uint(carID)); err != nil {
+		ctx.JSON(favoriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "price alert removed"})
+}
+
+// GetPriceAlerts обработчик для получения списка настроенных уведомлений
+func (c *FavoriteController) GetPriceAlerts(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	favorites, err := c.favoriteService.GetPriceAlerts(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, favorites)
+}
+
+// favoriteError maps favorite errors to HTTP status codes
+func favoriteError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrFavoriteNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidPriceAlert):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FinancingController struct {
+	financingService *services.FinancingService
+}
+
+func NewFinancingController(financingService *services.FinancingService) *FinancingController {
+	return &FinancingController{
+		financingService: financingService,
+	}
+}
+
+// GetFinancing handles GET /cars/:id/financing?down_payment=&term_months=&rate=|product_id=
+func (c *FinancingController) GetFinancing(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	query, err := services.ParseFinancingQuery(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := c.financingService.Calculate(uint(carID), query)
+	if err != nil {
+		ctx.JSON(financingError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, plan)
+}
+
+// ListProducts handles GET /financing-products
+func (c *FinancingController) ListProducts(ctx *gin.Context) {
+	products, err := c.financingService.ListProducts()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, products)
+}
+
+// GetProduct handles GET /financing-products/:id
+func (c *FinancingController) GetProduct(ctx *gin.Context) {
+	id, ok := financingProductID(ctx)
+	if !ok {
+		return
+	}
+
+	product, err := c.financingService.GetProduct(id)
+	if err != nil {
+		ctx.JSON(financingError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, product)
+}
+
+// CreateProduct handles POST /financing-products
+func (c *FinancingController) CreateProduct(ctx *gin.Context) {
+	var product models.FinancingProduct
+	if err := ctx.ShouldBindJSON(&product); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := c.financingService.CreateProduct(&product)
+	if err != nil {
+		ctx.JSON(financingError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// UpdateProduct handles PUT /financing-products/:id; the rate table is replaced as a whole
+func (c *FinancingController) UpdateProduct(ctx *gin.Context) {
+	id, ok := financingProductID(ctx)
+	if !ok {
+		return
+	}
+
+	var product models.FinancingProduct
+	if err := ctx.ShouldBindJSON(&product); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := c.financingService.UpdateProduct(id, &product)
+	if err != nil {
+		ctx.JSON(financingError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, updated)
+}
+
+// DeleteProduct handles DELETE /financing-products/:id
+func (c *FinancingController) DeleteProduct(ctx *gin.Context) {
+	id, ok := financingProductID(ctx)
+	if !ok {
+		return
+	}
+
+	if err := c.financingService.DeleteProduct(id); err != nil {
+		ctx.JSON(financingError(err), gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "financing product deleted"})
+}
+
+// financingProductID parses the product ID from the path and writes the error response itself
+func financingProductID(ctx *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid financing product ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// financingError maps financing errors to HTTP status codes
+func financingError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrCarNotFound), errors.Is(err, services.ErrFinancingProductNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidFinancing), errors.Is(err, models.ErrInvalidFinancingProduct):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrFinancingProductExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,244 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MessageController struct {
+	messageService *services.MessageService
+}
+
+func NewMessageController(messageService *services.MessageService) *MessageController {
+	return &MessageController{
+		messageService: messageService,
+	}
+}
+
+// MessageRequest is the body of a new message
+type MessageRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// StartThread handles POST /cars/:id/threads
+func (c *MessageController) StartThread(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req MessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thread, message, err := c.messageService.StartThread(userID, isAdmin(ctx), uint(carID), req.Body)
+	if err != nil {
+		ctx.JSON(messageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{"thread": thread, "message": message})
+}
+
+// GetThreads handles GET /api/threads
+func (c *MessageController) GetThreads(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	threads, total, err := c.messageService.ListThreads(userID, isAdmin(ctx), page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, threads, total, page, perPage))
+}
+
+// GetUnreadCount handles GET /api/threads/unread-count
+func (c *MessageController) GetUnreadCount(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	count, err := c.messageService.UnreadCount(userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// GetThread handles GET /api/threads/:id
+func (c *MessageController) GetThread(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread ID"})
+		return
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thread, err := c.messageService.GetThread(uint(id), userID, isAdmin(ctx), page, perPage)
+	if err != nil {
+		ctx.JSON(messageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, thread)
+}
+
+// PostMessage handles POST /api/threads/:id/messages
+func (c *MessageController) PostMessage(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread ID"})
+		return
+	}
+
+	var req MessageRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message, err := c.messageService.PostMessage(uint(id), userID, isAdmin(ctx), req.Body)
+	if err != nil {
+		ctx.JSON(messageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, message)
+}
+
+// MarkThreadRead handles POST /api/threads/:id/read
+func (c *MessageController) MarkThreadRead(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread ID"})
+		return
+	}
+
+	if err := c.messageService.MarkThreadRead(uint(id), userID, isAdmin(ctx)); err != nil {
+		ctx.JSON(messageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListAllThreads handles GET /api/admin/threads
+func (c *MessageController) ListAllThreads(ctx *gin.Context) {
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var carID, userID uint64
+	if raw := ctx.Query("car_id"); raw != "" {
+		if carID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+			return
+		}
+	}
+	if raw := ctx.Query("user_id"); raw != "" {
+		if userID, err = strconv.ParseUint(raw, 10, 32); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+			return
+		}
+	}
+
+	threads, total, err := c.messageService.ListAllThreads(uint(carID), uint(userID), page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, threads, total, page, perPage))
+}
+
+// ModerateThread handles GET /api/admin/threads/:id
+func (c *MessageController) ModerateThread(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread ID"})
+		return
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	thread, err := c.messageService.ModerateThread(uint(id), page, perPage)
+	if err != nil {
+		ctx.JSON(messageError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, thread)
+}
+
+// messageError maps messaging errors to HTTP status codes
+func messageError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrThreadNotFound), errors.Is(err, services.ErrCarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrThreadForbidden), errors.Is(err, services.ErrSenderBlocked):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrInvalidMessage), errors.Is(err, services.ErrThreadWithSelf):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
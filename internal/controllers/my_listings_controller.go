@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"net/http"
+
+	"Cars/internal/middleware"
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMyListingRoutes configures the listings of private sellers (SELLER).
+// Updates and deletes share the car handlers, which only let sellers change their own cars.
+func RegisterMyListingRoutes(router *gin.Engine) {
+	listings := router.Group("/api/my/listings")
+	listings.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("SELLER"))
+	{
+		listings.GET("", getMyListings)
+		listings.POST("", createMyListing)
+		listings.PUT("/:id", middleware.RequireIfMatch(), updateCar)
+		listings.PATCH("/:id", middleware.RequireIfMatch(), patchCar)
+		listings.DELETE("/:id", middleware.RequireIfMatch(), deleteCar)
+	}
+}
+
+// Сатушының өз хабарландырулары, соның ішінде сатылғандары
+func getMyListings(c *gin.Context) {
+	page, perPage, err := services.ParsePage(c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cars, total, err := services.ListSellerCars(c.GetUint("userID"), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newPaginatedResponse(c, cars, total, page, perPage))
+}
+
+// Сатушының жаңа хабарландыруын жариялау
+func createMyListing(c *gin.Context) {
+	var car models.Car
+	if err := c.ShouldBindJSON(&car); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.CreateListing(&car, c.GetUint("userID")); err != nil {
+		c.JSON(carWriteError(err), gin.H{"error": err.Error()})
+		return
+	}
+	if user := currentUser(c); user != nil {
+		car.Seller = user.ToSellerInfo()
+	}
+	setETag(c, car.Version)
+	c.JSON(http.StatusCreated, car)
+}
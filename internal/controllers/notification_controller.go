@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationService *services.NotificationService
+}
+
+func NewNotificationController(notificationService *services.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+// GetNotifications handles GET /api/notifications
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	unreadOnly := false
+	if raw := ctx.Query("unread"); raw != "" {
+		unreadOnly, err = strconv.ParseBool(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "unread must be true or false"})
+			return
+		}
+	}
+
+	notifications, total, err := c.notificationService.GetUserNotifications(userID, unreadOnly, page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, notifications, total, page, perPage))
+}
+
+// GetUnreadCount handles GET /api/notifications/unread-count
+func (c *NotificationController) GetUnreadCount(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	count, err := c.notificationService.UnreadCount(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"unread": count})
+}
+
+// MarkAsRead handles POST /api/notifications/:id/read
+func (c *NotificationController) MarkAsRead(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid notification ID"})
+		return
+	}
+
+	notification, err := c.notificationService.MarkAsRead(userID, uint(id))
+	if err != nil {
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notification)
+}
+
+// MarkAllAsRead handles POST /api/notifications/read-all
+func (c *NotificationController) MarkAllAsRead(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	updated, err := c.notificationService.MarkAllAsRead(userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OfferController struct {
+	offerService *services.OfferService
+}
+
+func NewOfferController(offerService *services.OfferService) *OfferController {
+	return &OfferController{
+		offerService: offerService,
+	}
+}
+
+// OfferRequest is the body of a new offer or a counter-offer
+type OfferRequest struct {
+	Amount  float64 `json:"amount" binding:"required"`
+	Message string  `json:"message"`
+}
+
+// OfferAnswerRequest is the optional body of accepting or rejecting an offer
+type OfferAnswerRequest struct {
+	Message string `json:"message"`
+}
+
+// SubmitOffer handles POST /cars/:id/offers
+func (c *OfferController) SubmitOffer(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req OfferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := c.offerService.SubmitOffer(userID, isAdmin(ctx), uint(carID), req.Amount, req.Message)
+	if err != nil {
+		ctx.JSON(offerError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, offer)
+}
+
+// GetMyOffers handles GET /api/offers
+func (c *OfferController) GetMyOffers(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status := models.OfferStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidOfferStatus.Error()})
+		return
+	}
+
+	offers, err := c.offerService.GetUserOffers(userID, isAdmin(ctx), status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offers)
+}
+
+// GetOffer handles GET /api/offers/:id
+func (c *OfferController) GetOffer(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	offer, err := c.offerService.GetOffer(uint(id), userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(offerError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offer)
+}
+
+// CounterOffer handles POST /api/offers/:id/counter
+func (c *OfferController) CounterOffer(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	var req OfferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := c.offerService.CounterOffer(uint(id), userID, isAdmin(ctx), req.Amount, req.Message)
+	if err != nil {
+		ctx.JSON(offerError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offer)
+}
+
+// AcceptOffer handles POST /api/offers/:id/accept
+func (c *OfferController) AcceptOffer(ctx *gin.Context) {
+	c.answerOffer(ctx, c.offerService.AcceptOffer)
+}
+
+// RejectOffer handles POST /api/offers/:id/reject
+func (c *OfferController) RejectOffer(ctx *gin.Context) {
+	c.answerOffer(ctx, c.offerService.RejectOffer)
+}
+
+// WithdrawOffer handles POST /api/offers/:id/withdraw
+func (c *OfferController) WithdrawOffer(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	offer, err := c.offerService.WithdrawOffer(uint(id), userID)
+	if err != nil {
+		ctx.JSON(offerError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offer)
+}
+
+// ListOffers handles GET /api/admin/offers
+func (c *OfferController) ListOffers(ctx *gin.Context) {
+	status := models.OfferStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidOfferStatus.Error()})
+		return
+	}
+
+	var carID uint64
+	if raw := ctx.Query("car_id"); raw != "" {
+		var err error
+		carID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+			return
+		}
+	}
+
+	offers, err := c.offerService.ListOffers(status, uint(carID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offers)
+}
+
+// answerOffer runs an accept or reject with the optional message from the body
+func (c *OfferController) answerOffer(ctx *gin.Context, answer func(id, actorID uint, admin bool, message string) (*models.Offer, error)) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid offer ID"})
+		return
+	}
+
+	var req OfferAnswerRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	offer, err := answer(uint(id), userID, isAdmin(ctx), req.Message)
+	if err != nil {
+		ctx.JSON(offerError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, offer)
+}
+
+// offerError maps offer errors to HTTP status codes
+func offerError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrOfferNotFound), errors.Is(err, services.ErrCarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrOfferForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidOffer), errors.Is(err, services.ErrOfferOwnCar):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrOfferExists), errors.Is(err, services.ErrOfferNotYourTurn),
+		errors.Is(err, services.ErrOfferExpired), errors.Is(err, services.ErrCarNotAvailable),
+		errors.Is(err, models.ErrIllegalOfferTransition):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
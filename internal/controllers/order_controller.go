@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrderController struct {
+	orderService *services.OrderService
+}
+
+func NewOrderController(orderService *services.OrderService) *OrderController {
+	return &OrderController{
+		orderService: orderService,
+	}
+}
+
+// CreateOrderRequest orders either a car at its list price or the car of an accepted offer
+type CreateOrderRequest struct {
+	CarID   uint `json:"car_id"`
+	OfferID uint `json:"offer_id"`
+}
+
+// OrderStatusRequest is the body of an order status change
+type OrderStatusRequest struct {
+	Status models.OrderStatus `json:"status" binding:"required"`
+	Reason string             `json:"reason"`
+}
+
+// CreateOrder handles POST /api/orders
+func (c *OrderController) CreateOrder(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req CreateOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (req.CarID == 0) == (req.OfferID == 0) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of car_id and offer_id is required"})
+		return
+	}
+
+	var order *models.Order
+	var err error
+	if req.OfferID != 0 {
+		order, err = c.orderService.CreateOrderFromOffer(userID, req.OfferID)
+	} else {
+		order, err = c.orderService.CreateOrder(userID, isAdmin(ctx), req.CarID)
+	}
+	if err != nil {
+		ctx.JSON(orderError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+// GetOrder handles GET /api/orders/:id
+func (c *OrderController) GetOrder(ctx *gin.Context) {
+	order, ok := c.loadOrder(ctx)
+	if !ok {
+		return
+	}
+	ctx.JSON(http.StatusOK, order)
+}
+
+// DownloadInvoice handles GET /api/orders/:id/invoice and sends the invoice as a PDF
+func (c *OrderController) DownloadInvoice(ctx *gin.Context) {
+	order, ok := c.loadOrder(ctx)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := services.WriteInvoicePDF(&buf, order); err != nil {
+		ctx.JSON(orderError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, order.Invoice.Number))
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+// UpdateOrderStatus handles POST /api/orders/:id/status
+func (c *OrderController) UpdateOrderStatus(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var req OrderStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := c.orderService.UpdateOrderStatus(uint(id), userID, isAdmin(ctx), req.Status, req.Reason)
+	if err != nil {
+		ctx.JSON(orderError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// GetMyOrders handles GET /api/profile/orders
+func (c *OrderController) GetMyOrders(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status, page, perPage, ok := orderListParams(ctx)
+	if !ok {
+		return
+	}
+
+	orders, total, err := c.orderService.ListUserOrders(userID, status, page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, orders, total, page, perPage))
+}
+
+// ListOrders handles GET /api/admin/orders
+func (c *OrderController) ListOrders(ctx *gin.Context) {
+	status, page, perPage, ok := orderListParams(ctx)
+	if !ok {
+		return
+	}
+
+	var ids [2]uint64
+	for i, name := range []string{"car_id", "buyer_id"} {
+		raw := ctx.Query(name)
+		if raw == "" {
+			continue
+		}
+		var err error
+		ids[i], err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+			return
+		}
+	}
+
+	orders, total, err := c.orderService.ListOrders(status, uint(ids[0]), uint(ids[1]), page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, orders, total, page, perPage))
+}
+
+// loadOrder reads the order from the path for its buyer, seller or an admin and writes the error response itself
+func (c *OrderController) loadOrder(ctx *gin.Context) (*models.Order, bool) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return nil, false
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return nil, false
+	}
+
+	order, err := c.orderService.GetOrder(uint(id), userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(orderError(err), gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return order, true
+}
+
+// orderListParams parses the status filter and pagination of order lists
+func orderListParams(ctx *gin.Context) (models.OrderStatus, int, int, bool) {
+	status := models.OrderStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidOrderStatus.Error()})
+		return "", 0, 0, false
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", 0, 0, false
+	}
+	return status, page, perPage, true
+}
+
+// orderError maps order errors to HTTP status codes
+func orderError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound), errors.Is(err, services.ErrOfferNotFound),
+		errors.Is(err, services.ErrCarNotFound), errors.Is(err, services.ErrInvoiceNotIssued):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrOrderForbidden), errors.Is(err, services.ErrOfferForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidOrder), errors.Is(err, services.ErrOrderOwnCar),
+		errors.Is(err, models.ErrInvalidOrderStatus):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrOrderExists), errors.Is(err, services.ErrOfferNotAccepted),
+		errors.Is(err, services.ErrOfferHoldLapsed), errors.Is(err, services.ErrCarNotAvailable),
+		errors.Is(err, services.ErrOrderPaid), errors.Is(err, models.ErrIllegalOrderTransition),
+		errors.Is(err, models.ErrIllegalStatusTransition):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PageLinks holds navigation links for a paginated response
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Last  string `json:"last"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+}
+
+// PaginatedResponse is the envelope returned by paginated list endpoints
+type PaginatedResponse struct {
+	Data       interface{} `json:"data"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"per_page"`
+	TotalPages int         `json:"total_pages"`
+	Links      PageLinks   `json:"links"`
+}
+
+// newPaginatedResponse wraps a page of results and builds links from the current request URL
+func newPaginatedResponse(ctx *gin.Context, data interface{}, total int64, page, perPage int) PaginatedResponse {
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+	lastPage := totalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := PageLinks{
+		Self:  pageLink(ctx, page, perPage),
+		First: pageLink(ctx, 1, perPage),
+		Last:  pageLink(ctx, lastPage, perPage),
+	}
+	if page > 1 {
+		links.Prev = pageLink(ctx, min(page-1, lastPage), perPage)
+	}
+	if page < totalPages {
+		links.Next = pageLink(ctx, page+1, perPage)
+	}
+
+	return PaginatedResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+		Links:      links,
+	}
+}
+
+// pageLink returns the request path with page and per_page replaced
+func pageLink(ctx *gin.Context, page, perPage int) string {
+	u := *ctx.Request.URL
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(perPage))
+	u.RawQuery = query.Encode()
+	u.Scheme = ""
+	u.Host = ""
+	return u.RequestURI()
+}
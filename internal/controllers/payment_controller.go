@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody limits the size of a provider callback
+const maxWebhookBody = 1 << 20
+
+type PaymentController struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentController(paymentService *services.PaymentService) *PaymentController {
+	return &PaymentController{
+		paymentService: paymentService,
+	}
+}
+
+// PaymentRequest is the body of a new payment; the method is provider-specific
+type PaymentRequest struct {
+	Method string `json:"method"`
+}
+
+// StartPayment handles POST /api/orders/:id/payments
+func (c *PaymentController) StartPayment(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var req PaymentRequest
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	payment, err := c.paymentService.StartPayment(ctx.Request.Context(), uint(id), userID, req.Method)
+	if err != nil {
+		ctx.JSON(paymentError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, payment)
+}
+
+// GetOrderPayments handles GET /api/orders/:id/payments
+func (c *PaymentController) GetOrderPayments(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	payments, err := c.paymentService.GetOrderPayments(uint(id), userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(paymentError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payments)
+}
+
+// HandleWebhook handles POST /api/payments/webhooks/:provider.
+// Redelivered events are acknowledged without being applied again.
+func (c *PaymentController) HandleWebhook(ctx *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxWebhookBody))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+
+	duplicate, err := c.paymentService.HandleWebhook(ctx.Request.Context(), ctx.Param("provider"), payload, ctx.GetHeader(services.PaymentSignatureHeader))
+	if err != nil {
+		ctx.JSON(paymentError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"received": true, "duplicate": duplicate})
+}
+
+// ListPayments handles GET /api/admin/payments
+func (c *PaymentController) ListPayments(ctx *gin.Context) {
+	status := models.PaymentStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidPaymentStatus.Error()})
+		return
+	}
+
+	var orderID uint64
+	if raw := ctx.Query("order_id"); raw != "" {
+		var err error
+		orderID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+			return
+		}
+	}
+
+	page, perPage, err := services.ParsePage(ctx.Request.URL.Query())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payments, total, err := c.paymentService.ListPayments(status, uint(orderID), page, perPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaginatedResponse(ctx, payments, total, page, perPage))
+}
+
+// RefundPayment handles POST /api/admin/payments/:id/refund
+func (c *PaymentController) RefundPayment(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid payment ID"})
+		return
+	}
+
+	payment, err := c.paymentService.RefundPayment(ctx.Request.Context(), uint(id), userID)
+	if err != nil {
+		ctx.JSON(paymentError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
+
+// paymentError maps payment errors to HTTP status codes
+func paymentError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound), errors.Is(err, services.ErrOrderNotFound),
+		errors.Is(err, services.ErrUnknownProvider):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrOrderForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrInvalidWebhookSignature):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrOrderNotPayable), errors.Is(err, services.ErrPaymentInProgress),
+		errors.Is(err, services.ErrPaymentNotRefundable), errors.Is(err, models.ErrIllegalPaymentTransition):
+		return http.StatusConflict
+	case errors.Is(err, services.ErrPaymentProvider):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationController struct {
+	recommendationService *services.RecommendationService
+}
+
+func NewRecommendationController(recommendationService *services.RecommendationService) *RecommendationController {
+	return &RecommendationController{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetRecommendations handles GET /api/recommendations?limit=10
+func (c *RecommendationController) GetRecommendations(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit := services.DefaultRecommendations
+	if raw := ctx.Query("limit"); raw != "" {
+		var err error
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > services.MaxRecommendations {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(services.MaxRecommendations)})
+			return
+		}
+	}
+
+	feed, err := c.recommendationService.GetRecommendations(userID, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feed)
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReservationController struct {
+	reservationService *services.ReservationService
+}
+
+func NewReservationController(reservationService *services.ReservationService) *ReservationController {
+	return &ReservationController{
+		reservationService: reservationService,
+	}
+}
+
+// ReserveCar handles POST /cars/:id/reservations
+func (c *ReservationController) ReserveCar(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	reservation, err := c.reservationService.ReserveCar(userID, uint(carID))
+	if err != nil {
+		ctx.JSON(reservationError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, reservation)
+}
+
+// GetMyReservations handles GET /api/reservations
+func (c *ReservationController) GetMyReservations(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status := models.ReservationStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidReservationStatus.Error()})
+		return
+	}
+
+	reservations, err := c.reservationService.GetUserReservations(userID, status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservations)
+}
+
+// CancelReservation handles POST /api/reservations/:id/cancel
+func (c *ReservationController) CancelReservation(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid reservation ID"})
+		return
+	}
+
+	reservation, err := c.reservationService.CancelReservation(uint(id), userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(reservationError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// ListReservations handles GET /api/admin/reservations
+func (c *ReservationController) ListReservations(ctx *gin.Context) {
+	status := models.ReservationStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidReservationStatus.Error()})
+		return
+	}
+
+	var carID uint64
+	if raw := ctx.Query("car_id"); raw != "" {
+		var err error
+		carID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+			return
+		}
+	}
+
+	reservations, err := c.reservationService.ListReservations(status, uint(carID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservations)
+}
+
+// reservationError maps reservation errors to HTTP status codes
+func reservationError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReservationNotFound), errors.Is(err, services.ErrCarNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrReservationForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrCarNotAvailable), errors.Is(err, services.ErrReservationNotActive):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
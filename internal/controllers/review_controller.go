@@ -3,6 +3,7 @@ package controllers
 import (
 	"Cars/internal/models"
 	"Cars/internal/services"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -28,6 +29,7 @@ func NewReviewController(reviewService *services.ReviewService) *ReviewControlle
 // @Param review body models.Review true "Review object"
 // @Success 201 {object} models.Review
 // @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /reviews [post]
 func (c *ReviewController) CreateReview(ctx *gin.Context) {
@@ -46,6 +48,10 @@ func (c *ReviewController) CreateReview(ctx *gin.Context) {
 	review.UserID = userID.(uint)
 
 	if err := c.reviewService.CreateReview(&review); err != nil {
+		if errors.Is(err, services.ErrCarNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -60,6 +66,7 @@ func (c *ReviewController) CreateReview(ctx *gin.Context) {
 // @Produce json
 // @Param id path int true "Review ID"
 // @Success 200 {object} models.Review
+// @Header 200 {string} ETag "Version of the review, to be sent back in If-Match"
 // @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /reviews/{id} [get]
@@ -80,6 +87,7 @@ func (c *ReviewController) GetReview(ctx *gin.Context) {
 		return
 	}
 
+	setETag(ctx, review.Version)
 	ctx.JSON(http.StatusOK, review)
 }
 
@@ -90,10 +98,13 @@ func (c *ReviewController) GetReview(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "Review ID"
+// @Param If-Match header string true "ETag of the review being updated"
 // @Param review body models.Review true "Review object"
 // @Success 200 {object} models.Review
 // @Failure 400 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /reviews/{id} [put]
 func (c *ReviewController) UpdateReview(ctx *gin.Context) {
@@ -131,14 +142,29 @@ func (c *ReviewController) UpdateReview(ctx *gin.Context) {
 		return
 	}
 
+	match, err := ifMatch(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	review.ID = uint(id)
 	review.UserID = userID.(uint)
+	review.CarID = existingReview.CarID
 
-	if err := c.reviewService.UpdateReview(&review); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	if err := c.reviewService.UpdateReview(&review, match); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrVersionMismatch):
+			status = http.StatusPreconditionFailed
+		case errors.Is(err, services.ErrReviewNotFound):
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, ErrorResponse{Error: err.Error()})
 		return
 	}
 
+	setETag(ctx, review.Version)
 	ctx.JSON(http.StatusOK, review)
 }
 
@@ -148,8 +174,11 @@ func (c *ReviewController) UpdateReview(ctx *gin.Context) {
 // @Tags reviews
 // @Produce json
 // @Param id path int true "Review ID"
+// @Param If-Match header string true "ETag of the review being deleted"
 // @Success 204 "No Content"
 // @Failure 404 {object} ErrorResponse
+// @Failure 412 {object} ErrorResponse
+// @Failure 428 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Router /reviews/{id} [delete]
 func (c *ReviewController) DeleteReview(ctx *gin.Context) {
@@ -181,8 +210,21 @@ func (c *ReviewController) DeleteReview(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.reviewService.DeleteReview(uint(id)); err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	match, err := ifMatch(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.reviewService.DeleteReview(uint(id), match); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrVersionMismatch):
+			status = http.StatusPreconditionFailed
+		case errors.Is(err, services.ErrReviewNotFound):
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SimilarCarsController struct {
+	similarCarsService *services.SimilarCarsService
+}
+
+func NewSimilarCarsController(similarCarsService *services.SimilarCarsService) *SimilarCarsController {
+	return &SimilarCarsController{
+		similarCarsService: similarCarsService,
+	}
+}
+
+// GetSimilarCars handles GET /cars/:id/similar?limit=10
+func (c *SimilarCarsController) GetSimilarCars(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	limit := services.DefaultSimilarCars
+	if raw := ctx.Query("limit"); raw != "" {
+		limit, err = strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > services.MaxSimilarCars {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(services.MaxSimilarCars)})
+			return
+		}
+	}
+
+	similar, err := c.similarCarsService.GetSimilarCars(uint(carID), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrCarNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, similar)
+}
@@ -0,0 +1,289 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"Cars/internal/models"
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TestDriveController struct {
+	testDriveService *services.TestDriveService
+}
+
+func NewTestDriveController(testDriveService *services.TestDriveService) *TestDriveController {
+	return &TestDriveController{
+		testDriveService: testDriveService,
+	}
+}
+
+// TestDriveSlotRequest is the body of test drive booking and rescheduling
+type TestDriveSlotRequest struct {
+	StartsAt time.Time `json:"starts_at" binding:"required"`
+	Notes    string    `json:"notes" binding:"max=500"`
+}
+
+// GetSlots handles GET /cars/:id/test-drive-slots
+func (c *TestDriveController) GetSlots(ctx *gin.Context) {
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	from, to, err := c.testDriveService.SlotRange(ctx.Query("from"), ctx.Query("to"), time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	slots, err := c.testDriveService.GetSlots(uint(carID), from, to)
+	if err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"from":  from.Format("2006-01-02"),
+		"to":    to.Format("2006-01-02"),
+		"slots": slots,
+	})
+}
+
+// BookTestDrive handles POST /cars/:id/test-drives
+func (c *TestDriveController) BookTestDrive(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	carID, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+		return
+	}
+
+	var req TestDriveSlotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	testDrive, err := c.testDriveService.BookTestDrive(userID, uint(carID), req.StartsAt, req.Notes)
+	if err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, testDrive)
+}
+
+// GetMyTestDrives handles GET /api/test-drives
+func (c *TestDriveController) GetMyTestDrives(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	status := models.TestDriveStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidTestDriveStatus.Error()})
+		return
+	}
+
+	testDrives, err := c.testDriveService.GetUserTestDrives(userID, status)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, testDrives)
+}
+
+// RescheduleTestDrive handles POST /api/test-drives/:id/reschedule
+func (c *TestDriveController) RescheduleTestDrive(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid test drive ID"})
+		return
+	}
+
+	var req TestDriveSlotRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	testDrive, err := c.testDriveService.RescheduleTestDrive(uint(id), userID, isAdmin(ctx), req.StartsAt)
+	if err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, testDrive)
+}
+
+// CancelTestDrive handles POST /api/test-drives/:id/cancel
+func (c *TestDriveController) CancelTestDrive(ctx *gin.Context) {
+	userID := ctx.GetUint("userID")
+	if userID == 0 {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid test drive ID"})
+		return
+	}
+
+	testDrive, err := c.testDriveService.CancelTestDrive(uint(id), userID, isAdmin(ctx))
+	if err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, testDrive)
+}
+
+// ListTestDrives handles GET /api/admin/test-drives
+func (c *TestDriveController) ListTestDrives(ctx *gin.Context) {
+	status := models.TestDriveStatus(ctx.Query("status"))
+	if status != "" && !status.IsValid() {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": models.ErrInvalidTestDriveStatus.Error()})
+		return
+	}
+
+	var carID uint64
+	if raw := ctx.Query("car_id"); raw != "" {
+		var err error
+		carID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid car ID"})
+			return
+		}
+	}
+
+	testDrives, err := c.testDriveService.ListTestDrives(status, uint(carID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, testDrives)
+}
+
+// ListAvailability handles GET /api/admin/test-drive-availability
+func (c *TestDriveController) ListAvailability(ctx *gin.Context) {
+	availability, err := c.testDriveService.ListAvailability()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// CreateAvailability handles POST /api/admin/test-drive-availability
+func (c *TestDriveController) CreateAvailability(ctx *gin.Context) {
+	var availability models.TestDriveAvailability
+	if err := ctx.ShouldBindJSON(&availability); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.testDriveService.CreateAvailability(&availability); err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, availability)
+}
+
+// DeleteAvailability handles DELETE /api/admin/test-drive-availability/:id
+func (c *TestDriveController) DeleteAvailability(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid availability ID"})
+		return
+	}
+
+	if err := c.testDriveService.DeleteAvailability(uint(id)); err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListBlackouts handles GET /api/admin/test-drive-blackouts
+func (c *TestDriveController) ListBlackouts(ctx *gin.Context) {
+	blackouts, err := c.testDriveService.ListBlackouts(ctx.Query("from"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, blackouts)
+}
+
+// CreateBlackout handles POST /api/admin/test-drive-blackouts
+func (c *TestDriveController) CreateBlackout(ctx *gin.Context) {
+	var blackout models.TestDriveBlackout
+	if err := ctx.ShouldBindJSON(&blackout); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := c.testDriveService.CreateBlackout(&blackout); err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, blackout)
+}
+
+// DeleteBlackout handles DELETE /api/admin/test-drive-blackouts/:id
+func (c *TestDriveController) DeleteBlackout(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid blackout ID"})
+		return
+	}
+
+	if err := c.testDriveService.DeleteBlackout(uint(id)); err != nil {
+		ctx.JSON(testDriveError(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// testDriveError maps test drive errors to HTTP status codes
+func testDriveError(err error) int {
+	switch {
+	case errors.Is(err, services.ErrTestDriveNotFound), errors.Is(err, services.ErrCarNotFound),
+		errors.Is(err, services.ErrAvailabilityNotFound), errors.Is(err, services.ErrBlackoutNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrTestDriveForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, models.ErrInvalidAvailability), errors.Is(err, models.ErrInvalidBlackout):
+		return http.StatusBadRequest
+	case errors.Is(err, services.ErrCarNotBookable), errors.Is(err, services.ErrSlotUnavailable),
+		errors.Is(err, services.ErrTestDriveClosed), errors.Is(err, services.ErrAvailabilityOverlap):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
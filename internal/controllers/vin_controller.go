@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+
+	"Cars/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DecodeVIN handles GET /vin/:vin/decode
+func DecodeVIN(ctx *gin.Context) {
+	info, err := services.DecodeVIN(ctx.Param("vin"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireIfMatch rejects writes without an If-Match header with 428 Precondition Required,
+// so clients cannot overwrite a resource they have not read
+func RequireIfMatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("If-Match") == "" {
+			c.AbortWithStatusJSON(http.StatusPreconditionRequired, gin.H{
+				"error": "If-Match header is required; send the ETag of the resource you are changing",
+			})
+			return
+		}
+		c.Next()
+	}
+}
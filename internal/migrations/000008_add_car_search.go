@@ -0,0 +1,51 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarSearch adds a maintained tsvector column with a GIN index for car full-text search.
+// Other dialects fall back to LIKE matching and need no schema changes.
+func AddCarSearch() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000008_add_car_search",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "postgres" {
+				return nil
+			}
+
+			// Генерируемая колонка пересчитывается PostgreSQL при каждом изменении строки
+			if !tx.Migrator().HasColumn("cars", "search_vector") {
+				if err := tx.Exec(`
+					ALTER TABLE cars
+					ADD COLUMN search_vector tsvector
+					GENERATED ALWAYS AS (
+						setweight(to_tsvector('simple', coalesce(brand, '')), 'A') ||
+						setweight(to_tsvector('simple', coalesce(model, '')), 'A') ||
+						setweight(to_tsvector('simple', coalesce(car_type, '')), 'B') ||
+						setweight(to_tsvector('simple', coalesce(transmission, '')), 'C')
+					) STORED
+				`).Error; err != nil {
+					return err
+				}
+			}
+
+			return tx.Exec(`CREATE INDEX IF NOT EXISTS idx_cars_search_vector ON cars USING GIN (search_vector)`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if tx.Dialector.Name() != "postgres" {
+				return nil
+			}
+
+			if err := tx.Exec(`DROP INDEX IF EXISTS idx_cars_search_vector`).Error; err != nil {
+				return err
+			}
+
+			if tx.Migrator().HasColumn("cars", "search_vector") {
+				return tx.Migrator().DropColumn("cars", "search_vector")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarStatus adds the status column to cars and creates the car_status_history table
+func AddCarStatus() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000009_add_car_status",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.Car{}, "status") {
+				if err := tx.Migrator().AddColumn(&models.Car{}, "Status"); err != nil {
+					return err
+				}
+			}
+
+			// Существующие машины считаем доступными
+			if err := tx.Exec(`UPDATE cars SET status = ? WHERE status IS NULL OR status = ''`, models.StatusAvailable).Error; err != nil {
+				return err
+			}
+
+			if !tx.Migrator().HasIndex(&models.Car{}, "Status") {
+				if err := tx.Migrator().CreateIndex(&models.Car{}, "Status"); err != nil {
+					return err
+				}
+			}
+
+			if !tx.Migrator().HasTable(&models.CarStatusHistory{}) {
+				if err := tx.AutoMigrate(&models.CarStatusHistory{}); err != nil {
+					return err
+				}
+
+				if err := tx.Exec(`
+					ALTER TABLE car_status_history
+					ADD CONSTRAINT fk_cars_status_history
+					FOREIGN KEY (car_id)
+					REFERENCES cars(id)
+					ON DELETE CASCADE
+				`).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("car_status_history"); err != nil {
+				return err
+			}
+
+			if tx.Migrator().HasColumn(&models.Car{}, "status") {
+				if err := tx.Migrator().DropColumn(&models.Car{}, "status"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddReservations creates the reservations table.
+// The partial unique index guarantees at most one active reservation per car.
+func AddReservations() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000010_add_reservations",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&models.Reservation{}); err != nil {
+				return err
+			}
+
+			return tx.Exec(`
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_reservations_active_car
+				ON reservations (car_id)
+				WHERE status = 'active'
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("reservations")
+		},
+	}
+}
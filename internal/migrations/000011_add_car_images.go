@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarImages creates the car_images table for car galleries
+func AddCarImages() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000011_add_car_images",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&models.CarImage{}) {
+				return nil
+			}
+
+			if err := tx.AutoMigrate(&models.CarImage{}); err != nil {
+				return err
+			}
+
+			return tx.Exec(`
+				ALTER TABLE car_images
+				ADD CONSTRAINT fk_cars_images
+				FOREIGN KEY (car_id)
+				REFERENCES cars(id)
+				ON DELETE CASCADE
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("car_images")
+		},
+	}
+}
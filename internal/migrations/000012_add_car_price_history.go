@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarPriceHistory creates the car_price_history table and price tracking columns on cars
+func AddCarPriceHistory() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000012_add_car_price_history",
+		Migrate: func(tx *gorm.DB) error {
+			for _, field := range []string{"PreviousPrice", "PriceChangedAt"} {
+				if !tx.Migrator().HasColumn(&models.Car{}, field) {
+					if err := tx.Migrator().AddColumn(&models.Car{}, field); err != nil {
+						return err
+					}
+				}
+			}
+
+			if tx.Migrator().HasTable(&models.CarPriceHistory{}) {
+				return nil
+			}
+
+			if err := tx.AutoMigrate(&models.CarPriceHistory{}); err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`
+				ALTER TABLE car_price_history
+				ADD CONSTRAINT fk_cars_price_history
+				FOREIGN KEY (car_id)
+				REFERENCES cars(id)
+				ON DELETE CASCADE
+			`).Error; err != nil {
+				return err
+			}
+
+			// Текущая цена каждой машины становится началом её истории
+			return tx.Exec(`
+				INSERT INTO car_price_history (car_id, new_price, source, created_at)
+				SELECT id, COALESCE(price, 0), ?, CURRENT_TIMESTAMP FROM cars
+			`, models.PriceSourceInitial).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("car_price_history"); err != nil {
+				return err
+			}
+
+			for _, column := range []string{"previous_price", "price_changed_at"} {
+				if tx.Migrator().HasColumn(&models.Car{}, column) {
+					if err := tx.Migrator().DropColumn(&models.Car{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
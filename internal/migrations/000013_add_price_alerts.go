@@ -0,0 +1,70 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddPriceAlerts adds alert settings to favorites and creates the notifications table
+func AddPriceAlerts() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000013_add_price_alerts",
+		Migrate: func(tx *gorm.DB) error {
+			for _, field := range []string{"AlertType", "AlertTargetPrice", "AlertNotifiedPrice"} {
+				if !tx.Migrator().HasColumn(&models.Favorite{}, field) {
+					if err := tx.Migrator().AddColumn(&models.Favorite{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			if !tx.Migrator().HasIndex(&models.Favorite{}, "AlertType") {
+				if err := tx.Migrator().CreateIndex(&models.Favorite{}, "AlertType"); err != nil {
+					return err
+				}
+			}
+
+			if tx.Migrator().HasTable(&models.Notification{}) {
+				return nil
+			}
+
+			if err := tx.AutoMigrate(&models.Notification{}); err != nil {
+				return err
+			}
+
+			if err := tx.Exec(`
+				ALTER TABLE notifications
+				ADD CONSTRAINT fk_users_notifications
+				FOREIGN KEY (user_id)
+				REFERENCES users(id)
+				ON DELETE CASCADE
+			`).Error; err != nil {
+				return err
+			}
+
+			// Уведомление остаётся в ящике даже после удаления машины
+			return tx.Exec(`
+				ALTER TABLE notifications
+				ADD CONSTRAINT fk_cars_notifications
+				FOREIGN KEY (car_id)
+				REFERENCES cars(id)
+				ON DELETE SET NULL
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable("notifications"); err != nil {
+				return err
+			}
+
+			for _, column := range []string{"alert_type", "alert_target_price", "alert_notified_price"} {
+				if tx.Migrator().HasColumn(&models.Favorite{}, column) {
+					if err := tx.Migrator().DropColumn(&models.Favorite{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
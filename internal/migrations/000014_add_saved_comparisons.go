@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddSavedComparisons creates the saved_comparisons table
+func AddSavedComparisons() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000014_add_saved_comparisons",
+		Migrate: func(tx *gorm.DB) error {
+			if tx.Migrator().HasTable(&models.SavedComparison{}) {
+				return nil
+			}
+
+			if err := tx.AutoMigrate(&models.SavedComparison{}); err != nil {
+				return err
+			}
+
+			return tx.Exec(`
+				ALTER TABLE saved_comparisons
+				ADD CONSTRAINT fk_users_saved_comparisons
+				FOREIGN KEY (user_id)
+				REFERENCES users(id)
+				ON DELETE CASCADE
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("saved_comparisons")
+		},
+	}
+}
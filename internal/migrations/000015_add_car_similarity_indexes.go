@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// carSimilarityIndexes back the candidate pre-filter of the similar cars query
+var carSimilarityIndexes = map[string]string{
+	"idx_cars_lower_car_type": "CREATE INDEX IF NOT EXISTS idx_cars_lower_car_type ON cars (LOWER(car_type))",
+	"idx_cars_lower_brand":    "CREATE INDEX IF NOT EXISTS idx_cars_lower_brand ON cars (LOWER(brand))",
+	"idx_cars_price":          "CREATE INDEX IF NOT EXISTS idx_cars_price ON cars (price)",
+}
+
+// AddCarSimilarityIndexes indexes the columns used to pick candidates for similar cars
+func AddCarSimilarityIndexes() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000015_add_car_similarity_indexes",
+		Migrate: func(tx *gorm.DB) error {
+			for _, stmt := range carSimilarityIndexes {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for name := range carSimilarityIndexes {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + name).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// carSpecFields are the car columns added together with the reference catalogs
+var carSpecFields = []string{
+	"FuelType", "Drivetrain", "Color", "Doors", "Seats", "Horsepower", "VIN", "Description",
+	"BrandID", "CarModelID", "CarTypeID",
+}
+
+// AddCarSpecsAndReferences adds the extended specification columns to cars, creates the brands,
+// car_models and car_types catalogs and back-fills them from the existing free-text values
+func AddCarSpecsAndReferences() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000016_add_car_specs_and_references",
+		Migrate: func(tx *gorm.DB) error {
+			for _, field := range carSpecFields {
+				if !tx.Migrator().HasColumn(&models.Car{}, field) {
+					if err := tx.Migrator().AddColumn(&models.Car{}, field); err != nil {
+						return err
+					}
+				}
+			}
+
+			if tx.Migrator().HasTable(&models.Brand{}) {
+				return nil
+			}
+
+			if err := tx.AutoMigrate(&models.Brand{}, &models.CarModel{}, &models.CarType{}); err != nil {
+				return err
+			}
+
+			statements := []string{
+				// Названия уникальны без учёта регистра
+				`CREATE UNIQUE INDEX idx_brands_lower_name ON brands (LOWER(name))`,
+				`CREATE UNIQUE INDEX idx_car_models_brand_lower_name ON car_models (brand_id, LOWER(name))`,
+				`CREATE UNIQUE INDEX idx_car_types_lower_name ON car_types (LOWER(name))`,
+
+				// Перенос существующих значений в справочники
+				`INSERT INTO brands (name, created_at, updated_at)
+					SELECT MIN(TRIM(brand)), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM cars
+					WHERE TRIM(COALESCE(brand, '')) <> ''
+					GROUP BY LOWER(TRIM(brand))`,
+				`INSERT INTO car_types (name, created_at, updated_at)
+					SELECT MIN(TRIM(car_type)), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM cars
+					WHERE TRIM(COALESCE(car_type, '')) <> ''
+					GROUP BY LOWER(TRIM(car_type))`,
+				`INSERT INTO car_models (brand_id, name, created_at, updated_at)
+					SELECT b.id, MIN(TRIM(c.model)), CURRENT_TIMESTAMP, CURRENT_TIMESTAMP FROM cars c
+					JOIN brands b ON LOWER(b.name) = LOWER(TRIM(c.brand))
+					WHERE TRIM(COALESCE(c.model, '')) <> ''
+					GROUP BY b.id, LOWER(TRIM(c.model))`,
+
+				`UPDATE cars SET brand_id = (
+					SELECT b.id FROM brands b WHERE LOWER(b.name) = LOWER(TRIM(cars.brand)))`,
+				`UPDATE cars SET car_type_id = (
+					SELECT t.id FROM car_types t WHERE LOWER(t.name) = LOWER(TRIM(cars.car_type)))`,
+				`UPDATE cars SET car_model_id = (
+					SELECT m.id FROM car_models m WHERE m.brand_id = cars.brand_id AND LOWER(m.name) = LOWER(TRIM(cars.model)))`,
+
+				// Текстовые колонки получают каноническое написание из справочников
+				`UPDATE cars SET brand = (SELECT b.name FROM brands b WHERE b.id = cars.brand_id) WHERE brand_id IS NOT NULL`,
+				`UPDATE cars SET model = (SELECT m.name FROM car_models m WHERE m.id = cars.car_model_id) WHERE car_model_id IS NOT NULL`,
+				`UPDATE cars SET car_type = (SELECT t.name FROM car_types t WHERE t.id = cars.car_type_id) WHERE car_type_id IS NOT NULL`,
+
+				// Справочную запись нельзя удалить, пока на неё ссылаются
+				`ALTER TABLE car_models ADD CONSTRAINT fk_brands_car_models
+					FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE RESTRICT`,
+				`ALTER TABLE cars ADD CONSTRAINT fk_brands_cars
+					FOREIGN KEY (brand_id) REFERENCES brands(id) ON DELETE RESTRICT`,
+				`ALTER TABLE cars ADD CONSTRAINT fk_car_models_cars
+					FOREIGN KEY (car_model_id) REFERENCES car_models(id) ON DELETE RESTRICT`,
+				`ALTER TABLE cars ADD CONSTRAINT fk_car_types_cars
+					FOREIGN KEY (car_type_id) REFERENCES car_types(id) ON DELETE RESTRICT`,
+			}
+			for _, stmt := range statements {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, constraint := range []string{"fk_brands_cars", "fk_car_models_cars", "fk_car_types_cars"} {
+				if tx.Migrator().HasConstraint(&models.Car{}, constraint) {
+					if err := tx.Migrator().DropConstraint(&models.Car{}, constraint); err != nil {
+						return err
+					}
+				}
+			}
+
+			if err := tx.Migrator().DropTable("car_models", "brands", "car_types"); err != nil {
+				return err
+			}
+
+			for _, field := range carSpecFields {
+				if tx.Migrator().HasColumn(&models.Car{}, field) {
+					if err := tx.Migrator().DropColumn(&models.Car{}, field); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarVINUnique makes the VIN unique among listed cars; empty VINs are not indexed
+func AddCarVINUnique() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000017_add_car_vin_unique",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.Exec("UPDATE cars SET vin = UPPER(TRIM(vin)) WHERE vin IS NOT NULL").Error; err != nil {
+				return err
+			}
+
+			// Повторный VIN остаётся у самого раннего объявления, у остальных очищается
+			err := tx.Exec(`UPDATE cars SET vin = '' WHERE vin <> '' AND EXISTS (
+				SELECT 1 FROM cars AS other WHERE other.vin = cars.vin AND other.id < cars.id)`).Error
+			if err != nil {
+				return err
+			}
+
+			return tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_cars_vin ON cars (vin) WHERE vin <> ''").Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_cars_vin").Error
+		},
+	}
+}
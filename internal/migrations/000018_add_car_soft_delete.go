@@ -0,0 +1,45 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarSoftDelete adds deleted_at to cars. The VIN stays unique only among cars that are not in the trash,
+// so a deleted listing does not block a new one of the same vehicle.
+func AddCarSoftDelete() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000018_add_car_soft_delete",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.Car{}, "DeletedAt") {
+				if err := tx.Migrator().AddColumn(&models.Car{}, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&models.Car{}, "DeletedAt") {
+				if err := tx.Migrator().CreateIndex(&models.Car{}, "DeletedAt"); err != nil {
+					return err
+				}
+			}
+
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_cars_vin").Error; err != nil {
+				return err
+			}
+			return tx.Exec("CREATE UNIQUE INDEX idx_cars_vin ON cars (vin) WHERE vin <> '' AND deleted_at IS NULL").Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec("DROP INDEX IF EXISTS idx_cars_vin").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("DELETE FROM cars WHERE deleted_at IS NOT NULL").Error; err != nil {
+				return err
+			}
+			if err := tx.Exec("CREATE UNIQUE INDEX idx_cars_vin ON cars (vin) WHERE vin <> ''").Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&models.Car{}, "DeletedAt")
+		},
+	}
+}
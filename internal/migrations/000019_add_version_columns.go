@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddVersionColumns adds the optimistic locking version to cars and reviews; existing rows start at 1
+func AddVersionColumns() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000019_add_version_columns",
+		Migrate: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&models.Car{}, &models.Review{}} {
+				if !tx.Migrator().HasColumn(model, "Version") {
+					if err := tx.Migrator().AddColumn(model, "Version"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, model := range []interface{}{&models.Car{}, &models.Review{}} {
+				if err := tx.Migrator().DropColumn(model, "Version"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
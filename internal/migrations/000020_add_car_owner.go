@@ -0,0 +1,41 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddCarOwner links cars to the seller who listed them; existing cars stay dealership listings
+func AddCarOwner() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000020_add_car_owner",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.Car{}, "OwnerID") {
+				if err := tx.Migrator().AddColumn(&models.Car{}, "OwnerID"); err != nil {
+					return err
+				}
+			}
+			if !tx.Migrator().HasIndex(&models.Car{}, "OwnerID") {
+				if err := tx.Migrator().CreateIndex(&models.Car{}, "OwnerID"); err != nil {
+					return err
+				}
+			}
+
+			// При удалении продавца его объявления остаются без владельца
+			if !tx.Migrator().HasConstraint(&models.Car{}, "Owner") {
+				return tx.Migrator().CreateConstraint(&models.Car{}, "Owner")
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if tx.Migrator().HasConstraint(&models.Car{}, "Owner") {
+				if err := tx.Migrator().DropConstraint(&models.Car{}, "Owner"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropColumn(&models.Car{}, "OwnerID")
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddTestDrives creates the test drive availability, blackout and booking tables.
+// The partial unique index guarantees that a slot of a car is booked at most once.
+func AddTestDrives() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000021_add_test_drives",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&models.TestDriveAvailability{}, &models.TestDriveBlackout{}, &models.TestDrive{}); err != nil {
+				return err
+			}
+
+			return tx.Exec(`
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_test_drives_booked_slot
+				ON test_drives (car_id, starts_at)
+				WHERE status = 'booked'
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("test_drives", "test_drive_blackouts", "test_drive_availabilities")
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddMessageThreads creates the conversation threads and their messages.
+// A buyer has a single thread per car, enforced by a unique index.
+func AddMessageThreads() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000022_add_message_threads",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.MessageThread{}, &models.Message{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("messages", "message_threads")
+		},
+	}
+}
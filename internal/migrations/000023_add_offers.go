@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddOffers creates the price offers and their negotiation history
+func AddOffers() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000023_add_offers",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Offer{}, &models.OfferEvent{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("offer_events", "offers")
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddOrders creates purchase orders, their invoices and the yearly invoice counters
+func AddOrders() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000024_add_orders",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Order{}, &models.Invoice{}, &models.InvoiceSequence{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("invoices", "orders", "invoice_sequences")
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddPayments creates payment attempts and the log of processed provider webhooks
+func AddPayments() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000025_add_payments",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Payment{}, &models.PaymentWebhookEvent{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("payment_webhook_events", "payments")
+		},
+	}
+}
@@ -0,0 +1,21 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddFinancingProducts creates financing products and their rate tables
+func AddFinancingProducts() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000026_add_financing_products",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.FinancingProduct{}, &models.FinancingRate{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable("financing_rates", "financing_products")
+		},
+	}
+}
@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// currencyModels are the models whose amounts carry a currency code
+var currencyModels = []interface{}{&models.Car{}, &models.Order{}, &models.Invoice{}, &models.Payment{}}
+
+// AddCurrencies creates the exchange rate table with the base currency and stores a currency with every price.
+// Existing prices are taken to be in the base currency.
+func AddCurrencies() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000027_add_currencies",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&models.ExchangeRate{}); err != nil {
+				return err
+			}
+			base := models.ExchangeRate{Currency: models.BaseCurrency, Rate: 1, Decimals: 2}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&base).Error; err != nil {
+				return err
+			}
+
+			for _, model := range currencyModels {
+				if !tx.Migrator().HasColumn(model, "Currency") {
+					if err := tx.Migrator().AddColumn(model, "Currency"); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, model := range currencyModels {
+				if err := tx.Migrator().DropColumn(model, "Currency"); err != nil {
+					return err
+				}
+			}
+			return tx.Migrator().DropTable("exchange_rates")
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddPriceHistoryCurrency stores the currency of every price history entry.
+// Existing entries are taken to be in the current currency of their car.
+func AddPriceHistoryCurrency() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000028_add_price_history_currency",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.CarPriceHistory{}, "Currency") {
+				if err := tx.Migrator().AddColumn(&models.CarPriceHistory{}, "Currency"); err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`
+				UPDATE car_price_history SET currency = cars.currency
+				FROM cars
+				WHERE cars.id = car_price_history.car_id
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.CarPriceHistory{}, "Currency")
+		},
+	}
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddOfferCurrency stores the currency an offer's amount is given in.
+// Existing offers are taken to be in the current currency of their car.
+func AddOfferCurrency() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000029_add_offer_currency",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.Offer{}, "Currency") {
+				if err := tx.Migrator().AddColumn(&models.Offer{}, "Currency"); err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`
+				UPDATE offers SET currency = cars.currency
+				FROM cars
+				WHERE cars.id = offers.car_id
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.Offer{}, "Currency")
+		},
+	}
+}
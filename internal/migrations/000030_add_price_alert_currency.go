@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"Cars/internal/models"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+// AddPriceAlertCurrency stores the currency a below_target alert's target price is given in.
+// Existing targets are taken to be in the current currency of their car.
+func AddPriceAlertCurrency() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "000030_add_price_alert_currency",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&models.Favorite{}, "AlertTargetCurrency") {
+				if err := tx.Migrator().AddColumn(&models.Favorite{}, "AlertTargetCurrency"); err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`
+				UPDATE favorites SET alert_target_currency = cars.currency
+				FROM cars
+				WHERE cars.id = favorites.car_id AND favorites.alert_target_price IS NOT NULL
+			`).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&models.Favorite{}, "AlertTargetCurrency")
+		},
+	}
+}
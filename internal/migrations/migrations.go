@@ -24,5 +24,28 @@ func GetMigrations(db *gorm.DB) *gormigrate.Gormigrate {
 		AddUserBlocked(),
 		FixReviewsCascade(),
 		AddFavorites(),
+		AddCarSearch(),
+		AddCarStatus(),
+		AddReservations(),
+		AddCarImages(),
+		AddCarPriceHistory(),
+		AddPriceAlerts(),
+		AddSavedComparisons(),
+		AddCarSimilarityIndexes(),
+		AddCarSpecsAndReferences(),
+		AddCarVINUnique(),
+		AddCarSoftDelete(),
+		AddVersionColumns(),
+		AddCarOwner(),
+		AddTestDrives(),
+		AddMessageThreads(),
+		AddOffers(),
+		AddOrders(),
+		AddPayments(),
+		AddFinancingProducts(),
+		AddCurrencies(),
+		AddPriceHistoryCurrency(),
+		AddOfferCurrency(),
+		AddPriceAlertCurrency(),
 	})
 }
@@ -2,23 +2,92 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type Car struct {
-	ID           uint     `json:"id" gorm:"primaryKey"`
-	Brand        string   `json:"brand"`
-	Model        string   `json:"model"`
-	CarType      string   `json:"car_type"`
-	Year         int      `json:"year"`
-	ImageURL     string   `json:"image_url"`
-	Mileage      float64  `json:"mileage"`
-	Transmission string   `json:"transmission"`
-	EngineVolume float64  `json:"engine_vol"`
-	Price        float64  `json:"price"`
-	IsNew        bool     `json:"is_new"`
-	AvgRating    float32  `json:"avg_rating" gorm:"default:0"`
-	Reviews      []Review `json:"reviews,omitempty" gorm:"foreignKey:CarID"`
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Brand        string    `json:"brand"`
+	Model        string    `json:"model"`
+	CarType      string    `json:"car_type"`
+	Year         int       `json:"year"`
+	ImageURL     string    `json:"image_url"`
+	Mileage      float64   `json:"mileage"`
+	Transmission string    `json:"transmission"`
+	EngineVolume float64   `json:"engine_vol"`
+	Price        float64   `json:"price"`
+	Currency     string    `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	IsNew        bool      `json:"is_new"`
+	Status       CarStatus `json:"status" gorm:"type:varchar(20);default:'available';index"`
+	AvgRating    float32   `json:"avg_rating" gorm:"default:0"`
+	Reviews      []Review  `json:"reviews,omitempty" gorm:"foreignKey:CarID"`
+
+	// Extended specification
+	FuelType    FuelType   `json:"fuel_type" gorm:"type:varchar(20)"`
+	Drivetrain  Drivetrain `json:"drivetrain" gorm:"type:varchar(10)"`
+	Color       string     `json:"color" gorm:"size:30"`
+	Doors       int        `json:"doors"`
+	Seats       int        `json:"seats"`
+	Horsepower  int        `json:"horsepower"`
+	VIN         string     `json:"vin" gorm:"size:17"`
+	Description string     `json:"description" gorm:"type:text"`
+
+	// References to the normalized catalogs; Brand, Model and CarType keep the canonical names
+	BrandID    *uint `json:"brand_id" gorm:"index"`
+	CarModelID *uint `json:"car_model_id" gorm:"index"`
+	CarTypeID  *uint `json:"car_type_id" gorm:"index"`
+
+	// Price change tracking, maintained by the price history service
+	PreviousPrice    *float64   `json:"previous_price"`
+	PriceChangedAt   *time.Time `json:"price_changed_at"`
+	PriceDropPercent float64    `json:"price_drop_percent" gorm:"-"`
+	PriceReduced     bool       `json:"price_reduced" gorm:"-"`
+
+	// Price in the currency requested by the caller, filled by ConvertCarPrices
+	ConvertedPrice    *float64 `json:"converted_price,omitempty" gorm:"-"`
+	ConvertedCurrency string   `json:"converted_currency,omitempty" gorm:"-"`
+
+	// Private seller who published the listing; nil for cars listed by the dealership
+	OwnerID *uint       `json:"owner_id" gorm:"index"`
+	Owner   *User       `json:"-" gorm:"foreignKey:OwnerID;constraint:OnDelete:SET NULL"`
+	Seller  *SellerInfo `json:"seller" gorm:"-"` // filled from Owner when it is preloaded
+
+	// Version is increased by every edit and is sent as the ETag for optimistic locking
+	Version int `json:"version" gorm:"not null;default:1"`
+
+	// Soft delete: deleted cars stay in the trash until the retention job purges them
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// BeforeCreate starts the version of a new car at 1, whatever the client sent
+func (c *Car) BeforeCreate(tx *gorm.DB) error {
+	c.Version = 1
+	return nil
+}
+
+// AfterFind fills the derived price fields and the seller profile after loading a car
+func (c *Car) AfterFind(tx *gorm.DB) error {
+	c.ComputePriceDrop()
+	if c.Owner != nil {
+		c.Seller = c.Owner.ToSellerInfo()
+	}
+	return nil
+}
+
+// ComputePriceDrop derives the percentage drop from the previous price
+func (c *Car) ComputePriceDrop() {
+	c.PriceDropPercent = 0
+	c.PriceReduced = false
+	if c.PreviousPrice == nil || *c.PreviousPrice <= 0 || c.Price >= *c.PreviousPrice {
+		return
+	}
+	c.PriceReduced = true
+	c.PriceDropPercent = math.Round((*c.PreviousPrice-c.Price) / *c.PreviousPrice * 10000) / 100
 }
 
 // CarStatus represents the current status of the car
@@ -31,6 +100,123 @@ const (
 	StatusReservation CarStatus = "reservation"
 )
 
+// carStatusTransitions lists the statuses a car may move to from each status.
+// Reverting a sold car is not listed here because only an admin may do it.
+var carStatusTransitions = map[CarStatus][]CarStatus{
+	StatusAvailable:   {StatusReservation, StatusMaintenance, StatusSold},
+	StatusReservation: {StatusAvailable, StatusSold},
+	StatusMaintenance: {StatusAvailable},
+	StatusSold:        {},
+}
+
+// IsValid reports whether the status is one of the known car statuses
+func (s CarStatus) IsValid() bool {
+	_, ok := carStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo checks whether a car may move from s to next.
+// A sold car is terminal unless an admin reverts it to available.
+func (s CarStatus) CanTransitionTo(next CarStatus, admin bool) error {
+	if !next.IsValid() {
+		return ErrInvalidCarStatus
+	}
+	if s == next {
+		return fmt.Errorf("%w: car is already %s", ErrIllegalStatusTransition, s)
+	}
+	if s == StatusSold && next == StatusAvailable && admin {
+		return nil
+	}
+	for _, allowed := range carStatusTransitions[s] {
+		if allowed == next {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalStatusTransition, s, next)
+}
+
+// FuelType is the fuel a car runs on
+type FuelType string
+
+const (
+	FuelPetrol   FuelType = "petrol"
+	FuelDiesel   FuelType = "diesel"
+	FuelHybrid   FuelType = "hybrid"
+	FuelElectric FuelType = "electric"
+	FuelLPG      FuelType = "lpg"
+)
+
+// IsValid reports whether the fuel type is known; an empty value means unspecified
+func (f FuelType) IsValid() bool {
+	switch f {
+	case "", FuelPetrol, FuelDiesel, FuelHybrid, FuelElectric, FuelLPG:
+		return true
+	}
+	return false
+}
+
+// Drivetrain is the set of driven wheels
+type Drivetrain string
+
+const (
+	DrivetrainFWD Drivetrain = "fwd"
+	DrivetrainRWD Drivetrain = "rwd"
+	DrivetrainAWD Drivetrain = "awd"
+	Drivetrain4WD Drivetrain = "4wd"
+)
+
+// IsValid reports whether the drivetrain is known; an empty value means unspecified
+func (d Drivetrain) IsValid() bool {
+	switch d {
+	case "", DrivetrainFWD, DrivetrainRWD, DrivetrainAWD, Drivetrain4WD:
+		return true
+	}
+	return false
+}
+
+// MaxCarDescription is the maximum length of a car description
+const MaxCarDescription = 5000
+
+// NormalizeSpecs trims and lower-cases the enumerated specification fields and defaults the currency
+func (c *Car) NormalizeSpecs() {
+	c.FuelType = FuelType(strings.ToLower(strings.TrimSpace(string(c.FuelType))))
+	c.Drivetrain = Drivetrain(strings.ToLower(strings.TrimSpace(string(c.Drivetrain))))
+	c.Color = strings.TrimSpace(c.Color)
+	c.VIN = strings.ToUpper(strings.TrimSpace(c.VIN))
+	c.Currency = NormalizeCurrency(c.Currency)
+	if c.Currency == "" {
+		c.Currency = BaseCurrency
+	}
+}
+
+// ValidateSpecs checks the extended specification fields; zero values mean unspecified
+func (c *Car) ValidateSpecs() error {
+	if !c.FuelType.IsValid() {
+		return ErrInvalidFuelType
+	}
+	if !c.Drivetrain.IsValid() {
+		return ErrInvalidDrivetrain
+	}
+	if c.Doors != 0 && (c.Doors < 2 || c.Doors > 6) {
+		return ErrInvalidDoors
+	}
+	if c.Seats != 0 && (c.Seats < 1 || c.Seats > 9) {
+		return ErrInvalidSeats
+	}
+	if c.Horsepower < 0 || c.Horsepower > 2000 {
+		return ErrInvalidHorsepower
+	}
+	if c.VIN != "" {
+		if err := ValidateVIN(c.VIN); err != nil {
+			return err
+		}
+	}
+	if len(c.Description) > MaxCarDescription {
+		return ErrDescriptionTooLong
+	}
+	return nil
+}
+
 // Validate performs basic validation of car data
 func (c *Car) Validate() error {
 	if c.Brand == "" {
@@ -48,7 +234,10 @@ func (c *Car) Validate() error {
 	if c.Price < 0 {
 		return ErrInvalidPrice
 	}
-	return nil
+	if !IsCurrencyCode(c.Currency) {
+		return ErrInvalidCurrency
+	}
+	return c.ValidateSpecs()
 }
 
 // Custom errors for car validation
@@ -58,4 +247,14 @@ var (
 	ErrInvalidYear         = errors.New("invalid year")
 	ErrInvalidEngineVolume = errors.New("invalid engine volume")
 	ErrInvalidPrice        = errors.New("invalid price")
+	ErrInvalidFuelType     = errors.New("fuel type must be petrol, diesel, hybrid, electric or lpg")
+	ErrInvalidDrivetrain   = errors.New("drivetrain must be fwd, rwd, awd or 4wd")
+	ErrInvalidDoors        = errors.New("doors must be between 2 and 6")
+	ErrInvalidSeats        = errors.New("seats must be between 1 and 9")
+	ErrInvalidHorsepower   = errors.New("invalid horsepower")
+	ErrInvalidVIN          = errors.New("VIN must be 17 characters of A-Z and 0-9 without I, O and Q")
+	ErrDescriptionTooLong  = fmt.Errorf("description must not exceed %d characters", MaxCarDescription)
+
+	ErrInvalidCarStatus        = errors.New("invalid car status")
+	ErrIllegalStatusTransition = errors.New("illegal car status transition")
 )
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// CarImage is a single picture in a car's gallery with its generated renditions
+type CarImage struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	CarID         uint      `json:"car_id" gorm:"not null;index"`
+	Position      int       `json:"position" gorm:"not null;default:0"`
+	IsPrimary     bool      `json:"is_primary" gorm:"default:false"`
+	MimeType      string    `json:"mime_type" gorm:"size:50"`
+	Size          int64     `json:"size"`
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	OriginalPath  string    `json:"-" gorm:"size:255;not null"`
+	MediumPath    string    `json:"-" gorm:"size:255;not null"`
+	ThumbnailPath string    `json:"-" gorm:"size:255;not null"`
+	OriginalURL   string    `json:"original_url" gorm:"-"`
+	MediumURL     string    `json:"medium_url" gorm:"-"`
+	ThumbnailURL  string    `json:"thumbnail_url" gorm:"-"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
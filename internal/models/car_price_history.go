@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Sources of a price change
+const (
+	PriceSourceInitial = "initial"
+	PriceSourceCreate  = "create"
+	PriceSourceUpdate  = "update"
+	PriceSourceImport  = "import"
+	PriceSourceBulk    = "bulk"
+)
+
+// CarPriceHistory records a single price of a car; OldPrice is nil for the first entry
+// and for the first entry after a change of currency
+type CarPriceHistory struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CarID     uint      `json:"car_id" gorm:"not null;index"`
+	OldPrice  *float64  `json:"old_price"`
+	NewPrice  float64   `json:"new_price" gorm:"not null"`
+	Currency  string    `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	ChangedBy *uint     `json:"changed_by"`
+	Source    string    `json:"source" gorm:"size:20;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// TableName overrides the pluralized table name
+func (CarPriceHistory) TableName() string {
+	return "car_price_history"
+}
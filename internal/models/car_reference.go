@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Brand is an entry of the car brand catalog
+type Brand struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CarModel is a model of a brand, e.g. Camry of Toyota
+type CarModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	BrandID   uint      `json:"brand_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:100;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// CarType is an entry of the body type catalog, e.g. sedan or suv
+type CarType struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Name      string    `json:"name" gorm:"size:50;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
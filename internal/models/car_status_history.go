@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// CarStatusHistory records a single change of a car's status
+type CarStatusHistory struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	CarID      uint      `json:"car_id" gorm:"not null;index"`
+	FromStatus CarStatus `json:"from_status" gorm:"type:varchar(20);not null"`
+	ToStatus   CarStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	ChangedBy  *uint     `json:"changed_by"` // nil when the change was made by the system
+	Reason     string    `json:"reason" gorm:"size:255"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName overrides the pluralized table name
+func (CarStatusHistory) TableName() string {
+	return "car_status_history"
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// BaseCurrency is the currency every exchange rate is quoted in; prices without a currency are in it
+const BaseCurrency = "USD"
+
+// MaxCurrencyDecimals is the largest number of decimals a currency may be rounded to
+const MaxCurrencyDecimals = 4
+
+// ExchangeRate is the value of one unit of a currency in BaseCurrency together with its rounding rule.
+// Converted prices are rounded to Decimals places, e.g. 2 for USD and 0 for KZT or JPY.
+type ExchangeRate struct {
+	Currency  string    `json:"currency" gorm:"primaryKey;size:3"`
+	Rate      float64   `json:"rate" gorm:"not null"`
+	Decimals  int       `json:"decimals" gorm:"not null"`
+	UpdatedBy *uint     `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Exchange rate errors
+var (
+	ErrInvalidCurrency     = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrInvalidExchangeRate = errors.New("invalid exchange rate")
+)
+
+// NormalizeCurrency trims and upper-cases a currency code
+func NormalizeCurrency(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// IsCurrencyCode reports whether code looks like an ISO 4217 code, e.g. "KZT"
+func IsCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate normalizes the currency code and checks the rate and the rounding rule.
+// The base currency is always worth exactly 1.
+func (r *ExchangeRate) Validate() error {
+	r.Currency = NormalizeCurrency(r.Currency)
+	if !IsCurrencyCode(r.Currency) {
+		return ErrInvalidCurrency
+	}
+	if r.Rate <= 0 || math.IsInf(r.Rate, 0) || math.IsNaN(r.Rate) {
+		return fmt.Errorf("%w: rate must be positive", ErrInvalidExchangeRate)
+	}
+	if r.Currency == BaseCurrency && r.Rate != 1 {
+		return fmt.Errorf("%w: the rate of %s is always 1", ErrInvalidExchangeRate, BaseCurrency)
+	}
+	if r.Decimals < 0 || r.Decimals > MaxCurrencyDecimals {
+		return fmt.Errorf("%w: decimals must be between 0 and %d", ErrInvalidExchangeRate, MaxCurrencyDecimals)
+	}
+	return nil
+}
+
+// Round rounds an amount by the rounding rule of the currency
+func (r *ExchangeRate) Round(amount float64) float64 {
+	scale := math.Pow10(r.Decimals)
+	return math.Round(amount*scale) / scale
+}
@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"time"
+)
 
 // Favorite представляет избранный автомобиль пользователя
 type Favorite struct {
@@ -10,6 +13,37 @@ type Favorite struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	User      User      `json:"user" gorm:"foreignKey:UserID"`
 	Car       Car       `json:"car" gorm:"foreignKey:CarID"`
+
+	// Price-drop alert; AlertType is empty when no alert is set
+	AlertType           PriceAlertType `json:"alert_type" gorm:"type:varchar(20);default:'';index"`
+	AlertTargetPrice    *float64       `json:"alert_target_price"`
+	AlertTargetCurrency string         `json:"alert_target_currency" gorm:"size:3;not null;default:''"` // currency of the car when the target was set
+	AlertNotifiedPrice  *float64       `json:"-"`                                                       // price of the last below_target notification
+}
+
+// PriceAlertType defines when a favorite notifies about a price change
+type PriceAlertType string
+
+// Price alert types
+const (
+	AlertAnyDrop     PriceAlertType = "any_drop"     // every price reduction
+	AlertBelowTarget PriceAlertType = "below_target" // the price falls to or below AlertTargetPrice
+)
+
+// ErrInvalidPriceAlert is returned for an unknown alert type or a missing target price
+var ErrInvalidPriceAlert = errors.New("alert type must be any_drop or below_target with a positive target_price")
+
+// ValidateAlert checks an alert type together with its target price
+func ValidateAlert(alertType PriceAlertType, target *float64) error {
+	switch alertType {
+	case AlertAnyDrop:
+		return nil
+	case AlertBelowTarget:
+		if target != nil && *target > 0 {
+			return nil
+		}
+	}
+	return ErrInvalidPriceAlert
 }
 
 // FavoriteResponse представляет ответ API для избранного автомобиля
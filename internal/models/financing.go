@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FinancingType is the kind of a financing product
+type FinancingType string
+
+const (
+	FinancingLoan  FinancingType = "loan"
+	FinancingLease FinancingType = "lease"
+)
+
+// MaxFinancingTerm is the longest financing term in months
+const MaxFinancingTerm = 120
+
+// FinancingProduct is a named loan or lease offer with interest rates by term.
+// For leases ResidualPercent of the price is left over at the end of the term.
+type FinancingProduct struct {
+	ID                    uint            `json:"id" gorm:"primaryKey"`
+	Name                  string          `json:"name" gorm:"size:100;not null;uniqueIndex"`
+	Type                  FinancingType   `json:"type" gorm:"type:varchar(10);not null;default:'loan'"`
+	MinDownPaymentPercent float64         `json:"min_down_payment_percent" gorm:"not null;default:0"`
+	ResidualPercent       float64         `json:"residual_percent" gorm:"not null;default:0"`
+	Rates                 []FinancingRate `json:"rates" gorm:"foreignKey:ProductID;constraint:OnDelete:CASCADE"`
+	CreatedAt             time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt             time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// FinancingRate is the annual interest rate, in percent, of a product for one term
+type FinancingRate struct {
+	ID         uint    `json:"-" gorm:"primaryKey"`
+	ProductID  uint    `json:"-" gorm:"not null;uniqueIndex:idx_financing_rates_term"`
+	TermMonths int     `json:"term_months" gorm:"not null;uniqueIndex:idx_financing_rates_term"`
+	AnnualRate float64 `json:"annual_rate" gorm:"not null"`
+}
+
+// ErrInvalidFinancingProduct is returned for a product that fails validation
+var ErrInvalidFinancingProduct = errors.New("invalid financing product")
+
+// Validate normalizes the name and type and checks the percentages and the rate table
+func (p *FinancingProduct) Validate() error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" || len([]rune(p.Name)) > 100 {
+		return fmt.Errorf("%w: name must be between 1 and 100 characters", ErrInvalidFinancingProduct)
+	}
+	p.Type = FinancingType(strings.ToLower(strings.TrimSpace(string(p.Type))))
+	if p.Type == "" {
+		p.Type = FinancingLoan
+	}
+	if p.Type != FinancingLoan && p.Type != FinancingLease {
+		return fmt.Errorf("%w: type must be loan or lease", ErrInvalidFinancingProduct)
+	}
+	if p.MinDownPaymentPercent < 0 || p.MinDownPaymentPercent >= 100 {
+		return fmt.Errorf("%w: min_down_payment_percent must be at least 0 and below 100", ErrInvalidFinancingProduct)
+	}
+	if p.Type == FinancingLoan && p.ResidualPercent != 0 {
+		return fmt.Errorf("%w: only leases have a residual_percent", ErrInvalidFinancingProduct)
+	}
+	if p.ResidualPercent < 0 || p.MinDownPaymentPercent+p.ResidualPercent >= 100 {
+		return fmt.Errorf("%w: residual_percent must leave part of the price to finance", ErrInvalidFinancingProduct)
+	}
+
+	if len(p.Rates) == 0 {
+		return fmt.Errorf("%w: at least one rate is required", ErrInvalidFinancingProduct)
+	}
+	seen := make(map[int]bool, len(p.Rates))
+	for _, rate := range p.Rates {
+		if rate.TermMonths < 1 || rate.TermMonths > MaxFinancingTerm {
+			return fmt.Errorf("%w: term_months must be between 1 and %d", ErrInvalidFinancingProduct, MaxFinancingTerm)
+		}
+		if seen[rate.TermMonths] {
+			return fmt.Errorf("%w: term of %d months is listed twice", ErrInvalidFinancingProduct, rate.TermMonths)
+		}
+		seen[rate.TermMonths] = true
+		if rate.AnnualRate < 0 || rate.AnnualRate > 100 {
+			return fmt.Errorf("%w: annual_rate must be between 0 and 100", ErrInvalidFinancingProduct)
+		}
+	}
+	return nil
+}
+
+// RateFor returns the annual rate of the product for a term
+func (p *FinancingProduct) RateFor(termMonths int) (float64, bool) {
+	for _, rate := range p.Rates {
+		if rate.TermMonths == termMonths {
+			return rate.AnnualRate, true
+		}
+	}
+	return 0, false
+}
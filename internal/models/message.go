@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxMessageLength limits the length of a single message
+const MaxMessageLength = 2000
+
+// MessageThread is a conversation between a buyer and the seller side of a car.
+// SellerID is the private seller who owns the car, or nil for dealership cars, which admins answer.
+type MessageThread struct {
+	ID               uint      `json:"id" gorm:"primaryKey"`
+	CarID            uint      `json:"car_id" gorm:"not null;uniqueIndex:idx_message_threads_car_buyer"`
+	BuyerID          uint      `json:"buyer_id" gorm:"not null;uniqueIndex:idx_message_threads_car_buyer;index"`
+	SellerID         *uint     `json:"seller_id" gorm:"index"`
+	BuyerLastReadID  uint      `json:"-" gorm:"not null;default:0"`
+	SellerLastReadID uint      `json:"-" gorm:"not null;default:0"`
+	LastMessageAt    time.Time `json:"last_message_at" gorm:"index"`
+	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
+	Car              Car       `json:"car" gorm:"foreignKey:CarID"`
+	Messages         []Message `json:"-" gorm:"foreignKey:ThreadID;constraint:OnDelete:CASCADE"`
+}
+
+// Message is a single message posted to a thread
+type Message struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ThreadID  uint      `json:"thread_id" gorm:"not null;index"`
+	SenderID  uint      `json:"sender_id" gorm:"not null"`
+	Body      string    `json:"body" gorm:"type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// ErrInvalidMessage is returned for an empty or too long message
+var ErrInvalidMessage = errors.New("invalid message")
+
+// Validate trims the body and checks its length
+func (m *Message) Validate() error {
+	m.Body = strings.TrimSpace(m.Body)
+	if m.Body == "" {
+		return fmt.Errorf("%w: body is required", ErrInvalidMessage)
+	}
+	if len([]rune(m.Body)) > MaxMessageLength {
+		return fmt.Errorf("%w: body must be at most %d characters", ErrInvalidMessage, MaxMessageLength)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Notification types
+const (
+	NotificationPriceDrop = "price_drop"
+)
+
+// Notification is an in-app message in a user's inbox
+type Notification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	Type      string     `json:"type" gorm:"size:30;not null"`
+	Title     string     `json:"title" gorm:"size:255;not null"`
+	Message   string     `json:"message" gorm:"type:text"`
+	CarID     *uint      `json:"car_id"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime;index"`
+}
+
+// IsRead reports whether the notification has been read
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OfferStatus represents the state of a price offer
+type OfferStatus string
+
+const (
+	OfferPending   OfferStatus = "pending"   // waiting for the seller side
+	OfferCountered OfferStatus = "countered" // waiting for the buyer
+	OfferAccepted  OfferStatus = "accepted"
+	OfferRejected  OfferStatus = "rejected"
+	OfferWithdrawn OfferStatus = "withdrawn"
+	OfferExpired   OfferStatus = "expired"
+)
+
+// offerTransitions lists the statuses an offer may move to from each status
+var offerTransitions = map[OfferStatus][]OfferStatus{
+	OfferPending:   {OfferCountered, OfferAccepted, OfferRejected, OfferWithdrawn, OfferExpired},
+	OfferCountered: {OfferPending, OfferAccepted, OfferRejected, OfferWithdrawn, OfferExpired},
+	OfferAccepted:  {},
+	OfferRejected:  {},
+	OfferWithdrawn: {},
+	OfferExpired:   {},
+}
+
+// IsValid reports whether the status is one of the known offer statuses
+func (s OfferStatus) IsValid() bool {
+	_, ok := offerTransitions[s]
+	return ok
+}
+
+// IsOpen reports whether the offer is still being negotiated
+func (s OfferStatus) IsOpen() bool {
+	return s == OfferPending || s == OfferCountered
+}
+
+// CanTransitionTo checks whether an offer may move from s to next
+func (s OfferStatus) CanTransitionTo(next OfferStatus) error {
+	for _, allowed := range offerTransitions[s] {
+		if allowed == next {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalOfferTransition, s, next)
+}
+
+// OfferAction is a step in the negotiation history of an offer
+type OfferAction string
+
+const (
+	OfferActionSubmitted OfferAction = "submitted"
+	OfferActionCountered OfferAction = "countered"
+	OfferActionAccepted  OfferAction = "accepted"
+	OfferActionRejected  OfferAction = "rejected"
+	OfferActionWithdrawn OfferAction = "withdrawn"
+	OfferActionExpired   OfferAction = "expired"
+)
+
+// Offer is a buyer's price proposal for a car and the negotiation that follows.
+// SellerID is the private seller who owns the car, or nil for dealership cars, which admins answer.
+// Amount is the price currently on the table, in Currency: the car's currency when the amount was proposed.
+type Offer struct {
+	ID            uint         `json:"id" gorm:"primaryKey"`
+	CarID         uint         `json:"car_id" gorm:"not null;index"`
+	BuyerID       uint         `json:"buyer_id" gorm:"not null;index"`
+	SellerID      *uint        `json:"seller_id" gorm:"index"`
+	Amount        float64      `json:"amount" gorm:"not null"`
+	Currency      string       `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	Status        OfferStatus  `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ExpiresAt     time.Time    `json:"expires_at" gorm:"not null;index"`
+	ReservationID *uint        `json:"reservation_id"`
+	CreatedAt     time.Time    `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time    `json:"updated_at" gorm:"autoUpdateTime"`
+	Car           Car          `json:"car" gorm:"foreignKey:CarID"`
+	Events        []OfferEvent `json:"events,omitempty" gorm:"foreignKey:OfferID;constraint:OnDelete:CASCADE"`
+}
+
+// OfferEvent records one step of the negotiation of an offer
+type OfferEvent struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	OfferID   uint        `json:"offer_id" gorm:"not null;index"`
+	ActorID   *uint       `json:"actor_id"` // nil when the step was taken by the system
+	Action    OfferAction `json:"action" gorm:"type:varchar(20);not null"`
+	Amount    *float64    `json:"amount"`
+	Message   string      `json:"message" gorm:"size:500"`
+	CreatedAt time.Time   `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Offer errors
+var (
+	ErrInvalidOfferStatus     = errors.New("invalid offer status")
+	ErrIllegalOfferTransition = errors.New("illegal offer transition")
+)
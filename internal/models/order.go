@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// OrderStatus represents the state of a purchase order
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"
+	OrderConfirmed OrderStatus = "confirmed"
+	OrderPaid      OrderStatus = "paid"
+	OrderDelivered OrderStatus = "delivered"
+	OrderCancelled OrderStatus = "cancelled"
+)
+
+// orderTransitions lists the statuses an order may move to from each status.
+// A paid order is only cancelled by refunding its payment.
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending:   {OrderConfirmed, OrderCancelled},
+	OrderConfirmed: {OrderPaid, OrderCancelled},
+	OrderPaid:      {OrderDelivered, OrderCancelled},
+	OrderDelivered: {},
+	OrderCancelled: {},
+}
+
+// IsValid reports whether the status is one of the known order statuses
+func (s OrderStatus) IsValid() bool {
+	_, ok := orderTransitions[s]
+	return ok
+}
+
+// IsOpen reports whether the order still holds its car
+func (s OrderStatus) IsOpen() bool {
+	return s == OrderPending || s == OrderConfirmed || s == OrderPaid
+}
+
+// CanTransitionTo checks whether an order may move from s to next
+func (s OrderStatus) CanTransitionTo(next OrderStatus) error {
+	if !next.IsValid() {
+		return ErrInvalidOrderStatus
+	}
+	for _, allowed := range orderTransitions[s] {
+		if allowed == next {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalOrderTransition, s, next)
+}
+
+// Order records the purchase of a car by a buyer, at list price or at the amount of an accepted offer.
+// SellerID is the private seller who owns the car, or nil for dealership cars.
+type Order struct {
+	ID           uint        `json:"id" gorm:"primaryKey"`
+	CarID        uint        `json:"car_id" gorm:"not null;index"`
+	BuyerID      uint        `json:"buyer_id" gorm:"not null;index"`
+	SellerID     *uint       `json:"seller_id" gorm:"index"`
+	OfferID      *uint       `json:"offer_id" gorm:"index"`
+	Price        float64     `json:"price" gorm:"not null"`
+	Currency     string      `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	Status       OrderStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	ConfirmedAt  *time.Time  `json:"confirmed_at"`
+	PaidAt       *time.Time  `json:"paid_at"`
+	DeliveredAt  *time.Time  `json:"delivered_at"`
+	CancelledAt  *time.Time  `json:"cancelled_at"`
+	CancelledBy  *uint       `json:"cancelled_by,omitempty"`
+	CancelReason string      `json:"cancel_reason,omitempty" gorm:"size:255"`
+	CreatedAt    time.Time   `json:"created_at" gorm:"autoCreateTime;index"`
+	UpdatedAt    time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+	Car          Car         `json:"car" gorm:"foreignKey:CarID"`
+	Invoice      *Invoice    `json:"invoice,omitempty" gorm:"foreignKey:OrderID"`
+}
+
+// Invoice is the numbered bill of an order. Buyer, seller and car details are copied
+// when it is issued, so later edits do not change an issued invoice.
+type Invoice struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	OrderID        uint      `json:"order_id" gorm:"not null;uniqueIndex"`
+	Number         string    `json:"number" gorm:"size:20;not null;uniqueIndex"`
+	Amount         float64   `json:"amount" gorm:"not null"`
+	Currency       string    `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	BuyerName      string    `json:"buyer_name" gorm:"size:100"`
+	BuyerEmail     string    `json:"buyer_email" gorm:"size:100"`
+	SellerName     string    `json:"seller_name" gorm:"size:100"`
+	CarDescription string    `json:"car_description" gorm:"size:255"`
+	VIN            string    `json:"vin" gorm:"size:17"`
+	IssuedAt       time.Time `json:"issued_at" gorm:"not null"`
+}
+
+// InvoiceSequence holds the last invoice number issued in a year
+type InvoiceSequence struct {
+	Year int `gorm:"primaryKey;autoIncrement:false"`
+	Last int `gorm:"not null;default:0"`
+}
+
+// Order errors
+var (
+	ErrInvalidOrderStatus     = errors.New("invalid order status")
+	ErrIllegalOrderTransition = errors.New("illegal order transition")
+)
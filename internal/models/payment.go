@@ -0,0 +1,88 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PaymentStatus represents the state of a payment attempt
+type PaymentStatus string
+
+const (
+	PaymentPending    PaymentStatus = "pending"
+	PaymentAuthorized PaymentStatus = "authorized"
+	PaymentCaptured   PaymentStatus = "captured"
+	PaymentFailed     PaymentStatus = "failed"
+	PaymentRefunded   PaymentStatus = "refunded"
+)
+
+// paymentTransitions lists the statuses a payment may move to from each status.
+// Providers may report a capture without a separate authorization.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentPending:    {PaymentAuthorized, PaymentCaptured, PaymentFailed},
+	PaymentAuthorized: {PaymentCaptured, PaymentFailed},
+	PaymentCaptured:   {PaymentRefunded},
+	PaymentFailed:     {},
+	PaymentRefunded:   {},
+}
+
+// IsValid reports whether the status is one of the known payment statuses
+func (s PaymentStatus) IsValid() bool {
+	_, ok := paymentTransitions[s]
+	return ok
+}
+
+// IsActive reports whether the payment may still take the buyer's money
+func (s PaymentStatus) IsActive() bool {
+	return s == PaymentPending || s == PaymentAuthorized
+}
+
+// CanTransitionTo checks whether a payment may move from s to next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) error {
+	if !next.IsValid() {
+		return ErrInvalidPaymentStatus
+	}
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrIllegalPaymentTransition, s, next)
+}
+
+// Payment is one attempt to pay an order through a payment provider.
+// ProviderRef is the provider's payment intent ID; it is empty when the provider could not be reached.
+type Payment struct {
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	OrderID       uint          `json:"order_id" gorm:"not null;index"`
+	Provider      string        `json:"provider" gorm:"size:30;not null;index:idx_payments_provider_ref"`
+	ProviderRef   string        `json:"provider_ref" gorm:"size:100;index:idx_payments_provider_ref"`
+	Method        string        `json:"method" gorm:"size:50"`
+	Amount        float64       `json:"amount" gorm:"not null"`
+	Currency      string        `json:"currency" gorm:"size:3;not null;default:'USD'"`
+	Status        PaymentStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	FailureReason string        `json:"failure_reason,omitempty" gorm:"size:255"`
+	CapturedAt    *time.Time    `json:"captured_at"`
+	RefundedAt    *time.Time    `json:"refunded_at"`
+	RefundedBy    *uint         `json:"refunded_by,omitempty"`
+	CreatedAt     time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// PaymentWebhookEvent records a processed provider callback so a redelivered event is applied only once
+type PaymentWebhookEvent struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	Provider    string    `json:"provider" gorm:"size:30;not null;uniqueIndex:idx_payment_webhook_events_event"`
+	EventID     string    `json:"event_id" gorm:"size:100;not null;uniqueIndex:idx_payment_webhook_events_event"`
+	Type        string    `json:"type" gorm:"size:50"`
+	ProviderRef string    `json:"provider_ref" gorm:"size:100"`
+	Payload     string    `json:"payload" gorm:"type:text"`
+	ReceivedAt  time.Time `json:"received_at" gorm:"autoCreateTime"`
+}
+
+// Payment errors
+var (
+	ErrInvalidPaymentStatus     = errors.New("invalid payment status")
+	ErrIllegalPaymentTransition = errors.New("illegal payment transition")
+)
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// ReservationStatus represents the state of a car reservation
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationCancelled ReservationStatus = "cancelled"
+	ReservationExpired   ReservationStatus = "expired"
+	ReservationCompleted ReservationStatus = "completed"
+)
+
+// IsValid reports whether the status is one of the known reservation statuses
+func (s ReservationStatus) IsValid() bool {
+	switch s {
+	case ReservationActive, ReservationCancelled, ReservationExpired, ReservationCompleted:
+		return true
+	}
+	return false
+}
+
+// Reservation is a time-boxed hold of a car by a buyer
+type Reservation struct {
+	ID          uint              `json:"id" gorm:"primaryKey"`
+	CarID       uint              `json:"car_id" gorm:"not null;index"`
+	UserID      uint              `json:"user_id" gorm:"not null;index"`
+	Status      ReservationStatus `json:"status" gorm:"type:varchar(20);not null;default:'active';index"`
+	ExpiresAt   time.Time         `json:"expires_at" gorm:"not null;index"`
+	CancelledBy *uint             `json:"cancelled_by,omitempty"`
+	CancelledAt *time.Time        `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+	Car         Car               `json:"car" gorm:"foreignKey:CarID"`
+}
+
+// Custom errors for reservations
+var (
+	ErrInvalidReservationStatus = errors.New("invalid reservation status")
+)
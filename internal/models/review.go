@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // Review represents a car review with rating and comment
@@ -14,9 +16,18 @@ type Review struct {
 	Comment   string    `json:"comment"`
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	Version   int       `json:"version" gorm:"not null;default:1"` // increased by every edit, sent as the ETag
 	Car       Car       `json:"car" gorm:"foreignKey:CarID"`
 }
 
+// BeforeCreate starts the version of a new review at 1
+func (r *Review) BeforeCreate(tx *gorm.DB) error {
+	if r.Version == 0 {
+		r.Version = 1
+	}
+	return nil
+}
+
 // Validate performs basic validation of review data
 func (r *Review) Validate() error {
 	if r.CarID == 0 {
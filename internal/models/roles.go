@@ -4,6 +4,7 @@ type Role string
 
 const (
 	RoleUser       Role = "USER"
+	RoleSeller     Role = "SELLER" // private seller who manages their own listings
 	RoleAdmin      Role = "ADMIN"
 	RoleSuperAdmin Role = "SUPER_ADMIN"
 )
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SavedComparison is a set of cars a user saved to compare again later
+type SavedComparison struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"size:100"`
+	CarIDs    []uint    `json:"car_ids" gorm:"serializer:json;type:text;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
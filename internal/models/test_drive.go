@@ -0,0 +1,109 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TestDriveStatus represents the state of a test drive booking
+type TestDriveStatus string
+
+const (
+	TestDriveBooked    TestDriveStatus = "booked"
+	TestDriveCancelled TestDriveStatus = "cancelled"
+)
+
+// IsValid reports whether the status is one of the known test drive statuses
+func (s TestDriveStatus) IsValid() bool {
+	return s == TestDriveBooked || s == TestDriveCancelled
+}
+
+// TestDrive is a booked slot for a buyer to drive a car
+type TestDrive struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	CarID       uint            `json:"car_id" gorm:"not null;index"`
+	UserID      uint            `json:"user_id" gorm:"not null;index"`
+	StartsAt    time.Time       `json:"starts_at" gorm:"not null;index"`
+	EndsAt      time.Time       `json:"ends_at" gorm:"not null"`
+	Status      TestDriveStatus `json:"status" gorm:"type:varchar(20);not null;default:'booked';index"`
+	Notes       string          `json:"notes" gorm:"size:500"`
+	CancelledBy *uint           `json:"cancelled_by,omitempty"`
+	CancelledAt *time.Time      `json:"cancelled_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time       `json:"updated_at" gorm:"autoUpdateTime"`
+	Car         Car             `json:"car" gorm:"foreignKey:CarID"`
+}
+
+// TestDriveAvailability is a weekly template of opening hours for test drives.
+// Templates without a car apply to every car that has no templates of its own.
+type TestDriveAvailability struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	CarID       *uint     `json:"car_id" gorm:"index"`
+	Weekday     int       `json:"weekday" gorm:"not null"` // 0 = Sunday ... 6 = Saturday
+	StartTime   string    `json:"start_time" gorm:"type:varchar(5);not null"`
+	EndTime     string    `json:"end_time" gorm:"type:varchar(5);not null"`
+	SlotMinutes int       `json:"slot_minutes" gorm:"not null;default:30"`
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TestDriveBlackout closes a whole day for test drives, for one car or for every car
+type TestDriveBlackout struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CarID     *uint     `json:"car_id" gorm:"index"`
+	Date      string    `json:"date" gorm:"type:varchar(10);not null;index"` // YYYY-MM-DD
+	Reason    string    `json:"reason" gorm:"size:200"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// Test drive validation errors
+var (
+	ErrInvalidTestDriveStatus = errors.New("invalid test drive status")
+	ErrInvalidAvailability    = errors.New("invalid test drive availability")
+	ErrInvalidBlackout        = errors.New("invalid test drive blackout")
+)
+
+// TimeOfDay parses a "HH:MM" clock time and returns it as minutes after midnight
+func TimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil || len(s) != 5 {
+		return 0, fmt.Errorf("time must be HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Validate checks the weekday, the opening hours and the slot length
+func (a *TestDriveAvailability) Validate() error {
+	if a.Weekday < 0 || a.Weekday > 6 {
+		return fmt.Errorf("%w: weekday must be between 0 (Sunday) and 6 (Saturday)", ErrInvalidAvailability)
+	}
+	if a.SlotMinutes == 0 {
+		a.SlotMinutes = 30
+	}
+	if a.SlotMinutes < 15 || a.SlotMinutes > 240 {
+		return fmt.Errorf("%w: slot_minutes must be between 15 and 240", ErrInvalidAvailability)
+	}
+	start, err := TimeOfDay(a.StartTime)
+	if err != nil {
+		return fmt.Errorf("%w: start_time: %v", ErrInvalidAvailability, err)
+	}
+	end, err := TimeOfDay(a.EndTime)
+	if err != nil {
+		return fmt.Errorf("%w: end_time: %v", ErrInvalidAvailability, err)
+	}
+	if end-start < a.SlotMinutes {
+		return fmt.Errorf("%w: end_time must leave room for at least one slot after start_time", ErrInvalidAvailability)
+	}
+	return nil
+}
+
+// Validate checks the date of the blackout
+func (b *TestDriveBlackout) Validate() error {
+	if _, err := time.Parse("2006-01-02", b.Date); err != nil {
+		return fmt.Errorf("%w: date must be YYYY-MM-DD", ErrInvalidBlackout)
+	}
+	if len(b.Reason) > 200 {
+		return fmt.Errorf("%w: reason must be at most 200 characters", ErrInvalidBlackout)
+	}
+	return nil
+}
@@ -25,6 +25,22 @@ type UserResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// SellerInfo is the public profile of the seller shown on a listing
+type SellerInfo struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	MemberSince time.Time `json:"member_since"`
+}
+
+// ToSellerInfo converts a User to the public seller profile
+func (u *User) ToSellerInfo() *SellerInfo {
+	return &SellerInfo{
+		ID:          u.ID,
+		Name:        u.Name,
+		MemberSince: u.CreatedAt,
+	}
+}
+
 // ToUserResponse converts a User to UserResponse
 func (u *User) ToUserResponse() UserResponse {
 	return UserResponse{
@@ -0,0 +1,60 @@
+package models
+
+import "errors"
+
+// ErrInvalidVINCheckDigit is returned when position 9 of a VIN does not match its check digit
+var ErrInvalidVINCheckDigit = errors.New("VIN check digit does not match")
+
+var (
+	vinTransliteration = map[byte]int{
+		'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+		'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+		'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+	}
+	vinWeights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+)
+
+// ValidVINFormat reports whether vin is 17 characters of A-Z and 0-9 without I, O and Q (ISO 3779)
+func ValidVINFormat(vin string) bool {
+	if len(vin) != 17 {
+		return false
+	}
+	for i := 0; i < len(vin); i++ {
+		c := vin[i]
+		if c >= '0' && c <= '9' {
+			continue
+		}
+		if _, ok := vinTransliteration[c]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// VINCheckDigit computes the check digit of a well-formed VIN: the weighted sum of the
+// transliterated characters modulo 11, with 10 written as X
+func VINCheckDigit(vin string) byte {
+	sum := 0
+	for i := 0; i < len(vin); i++ {
+		value, ok := vinTransliteration[vin[i]]
+		if !ok {
+			value = int(vin[i] - '0')
+		}
+		sum += value * vinWeights[i]
+	}
+	if rem := sum % 11; rem != 10 {
+		return byte('0' + rem)
+	}
+	return 'X'
+}
+
+// ValidateVIN checks the format and the check digit of a normalized VIN
+func ValidateVIN(vin string) error {
+	if !ValidVINFormat(vin) {
+		return ErrInvalidVIN
+	}
+	if VINCheckDigit(vin) != vin[8] {
+		return ErrInvalidVINCheckDigit
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCarImageRoutes configures all routes for car galleries
+func SetupCarImageRoutes(router *gin.Engine, carImageController *controllers.CarImageController) {
+	// Галерея доступна всем
+	router.GET("/cars/:id/images", carImageController.GetCarImages)
+
+	// Загрузка и управление изображениями только для администраторов
+	images := router.Group("/cars/:id/images")
+	images.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		images.POST("", carImageController.UploadImages)
+		images.PUT("/order", carImageController.ReorderImages)
+		images.PUT("/:image_id/primary", carImageController.SetPrimaryImage)
+		images.DELETE("/:image_id", carImageController.DeleteImage)
+	}
+}
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCarReferenceRoutes configures the brand, model and car type catalogs
+func SetupCarReferenceRoutes(router *gin.Engine, referenceController *controllers.CarReferenceController) {
+	// Справочники доступны всем, например для выпадающих списков
+	router.GET("/brands", referenceController.ListBrands)
+	router.GET("/brands/:id/models", referenceController.ListModels)
+	router.GET("/car-models", referenceController.ListModels)
+	router.GET("/car-types", referenceController.ListCarTypes)
+
+	// Изменение справочников только для администраторов
+	admin := router.Group("")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.POST("/brands", referenceController.CreateBrand)
+		admin.PUT("/brands/:id", referenceController.UpdateBrand)
+		admin.DELETE("/brands/:id", referenceController.DeleteBrand)
+		admin.POST("/brands/:id/models", referenceController.CreateModel)
+
+		admin.PUT("/car-models/:id", referenceController.UpdateModel)
+		admin.DELETE("/car-models/:id", referenceController.DeleteModel)
+
+		admin.POST("/car-types", referenceController.CreateCarType)
+		admin.PUT("/car-types/:id", referenceController.UpdateCarType)
+		admin.DELETE("/car-types/:id", referenceController.DeleteCarType)
+	}
+}
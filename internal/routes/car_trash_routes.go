@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCarTrashRoutes configures the trash of deleted cars (ADMIN, SUPER_ADMIN)
+func SetupCarTrashRoutes(router *gin.Engine, trashController *controllers.CarTrashController) {
+	admin := router.Group("")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("/admin/cars/trash", trashController.ListTrash)
+		admin.POST("/cars/:id/restore", trashController.RestoreCar)
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupComparisonRoutes configures car comparison and saved comparison routes
+func SetupComparisonRoutes(router *gin.Engine, comparisonController *controllers.ComparisonController) {
+	router.GET("/cars/compare", comparisonController.CompareCars)
+
+	// Сохранённые сравнения текущего пользователя
+	comparisons := router.Group("/api/comparisons")
+	comparisons.Use(middleware.AuthMiddleware())
+	{
+		comparisons.GET("", comparisonController.GetSavedComparisons)
+		comparisons.POST("", comparisonController.SaveComparison)
+		comparisons.GET("/:id", comparisonController.OpenSavedComparison)
+		comparisons.DELETE("/:id", comparisonController.DeleteSavedComparison)
+	}
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupExchangeRateRoutes configures the exchange rate table used to convert prices
+func SetupExchangeRateRoutes(router *gin.Engine, exchangeRateController *controllers.ExchangeRateController) {
+	router.GET("/exchange-rates", exchangeRateController.ListRates)
+
+	// Курсы вводят администраторы вручную
+	admin := router.Group("")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.PUT("/exchange-rates/:currency", exchangeRateController.SetRate)
+		admin.DELETE("/exchange-rates/:currency", exchangeRateController.DeleteRate)
+	}
+}
@@ -24,5 +24,10 @@ func SetupFavoriteRoutes(router *gin.Engine, favoriteController *controllers.Fav
 
 		// Проверить, находится ли автомобиль в избранном
 		favorites.GET("/:car_id", favoriteController.IsFavorite)
+
+		// Уведомления о снижении цены
+		favorites.GET("/alerts", favoriteController.GetPriceAlerts)
+		favorites.PUT("/:car_id/alert", favoriteController.SetPriceAlert)
+		favorites.DELETE("/:car_id/alert", favoriteController.RemovePriceAlert)
 	}
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupFinancingRoutes configures the financing calculator and financing products
+func SetupFinancingRoutes(router *gin.Engine, financingController *controllers.FinancingController) {
+	// Калькулятор кредита и лизинга на странице автомобиля
+	router.GET("/cars/:id/financing", financingController.GetFinancing)
+	router.GET("/financing-products", financingController.ListProducts)
+	router.GET("/financing-products/:id", financingController.GetProduct)
+
+	// Программы финансирования настраивают администраторы
+	admin := router.Group("")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.POST("/financing-products", financingController.CreateProduct)
+		admin.PUT("/financing-products/:id", financingController.UpdateProduct)
+		admin.DELETE("/financing-products/:id", financingController.DeleteProduct)
+	}
+}
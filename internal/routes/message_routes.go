@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupMessageRoutes configures buyer–seller conversations and their moderation
+func SetupMessageRoutes(router *gin.Engine, messageController *controllers.MessageController) {
+	// Начать переписку со страницы автомобиля
+	cars := router.Group("/cars").Use(middleware.AuthMiddleware())
+	{
+		cars.POST("/:id/threads", messageController.StartThread)
+	}
+
+	// Переписки текущего пользователя
+	threads := router.Group("/api/threads")
+	threads.Use(middleware.AuthMiddleware())
+	{
+		threads.GET("", messageController.GetThreads)
+		threads.GET("/unread-count", messageController.GetUnreadCount)
+		threads.GET("/:id", messageController.GetThread)
+		threads.POST("/:id/messages", messageController.PostMessage)
+		threads.POST("/:id/read", messageController.MarkThreadRead)
+	}
+
+	// Просмотр любой переписки для модерации, только чтение
+	moderation := router.Group("/api/admin/threads")
+	moderation.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("SUPER_ADMIN"))
+	{
+		moderation.GET("", messageController.ListAllThreads)
+		moderation.GET("/:id", messageController.ModerateThread)
+	}
+}
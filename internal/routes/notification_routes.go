@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupNotificationRoutes configures the in-app notifications inbox
+func SetupNotificationRoutes(router *gin.Engine, notificationController *controllers.NotificationController) {
+	notifications := router.Group("/api/notifications")
+	notifications.Use(middleware.AuthMiddleware())
+	{
+		notifications.GET("", notificationController.GetNotifications)
+		notifications.GET("/unread-count", notificationController.GetUnreadCount)
+		notifications.POST("/read-all", notificationController.MarkAllAsRead)
+		notifications.POST("/:id/read", notificationController.MarkAsRead)
+	}
+}
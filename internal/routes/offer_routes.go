@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupOfferRoutes configures price offers and their negotiation
+func SetupOfferRoutes(router *gin.Engine, offerController *controllers.OfferController) {
+	// Предложение цены со страницы автомобиля
+	cars := router.Group("/cars").Use(middleware.AuthMiddleware())
+	{
+		cars.POST("/:id/offers", offerController.SubmitOffer)
+	}
+
+	// Предложения текущего пользователя как покупателя и как продавца
+	offers := router.Group("/api/offers")
+	offers.Use(middleware.AuthMiddleware())
+	{
+		offers.GET("", offerController.GetMyOffers)
+		offers.GET("/:id", offerController.GetOffer)
+		offers.POST("/:id/counter", offerController.CounterOffer)
+		offers.POST("/:id/accept", offerController.AcceptOffer)
+		offers.POST("/:id/reject", offerController.RejectOffer)
+		offers.POST("/:id/withdraw", offerController.WithdrawOffer)
+	}
+
+	// Все предложения для администраторов
+	admin := router.Group("/api/admin/offers")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("", offerController.ListOffers)
+	}
+}
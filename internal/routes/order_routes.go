@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupOrderRoutes configures purchase orders and their invoices
+func SetupOrderRoutes(router *gin.Engine, orderController *controllers.OrderController) {
+	// Заказы покупателя и продавца
+	orders := router.Group("/api/orders")
+	orders.Use(middleware.AuthMiddleware())
+	{
+		orders.POST("", orderController.CreateOrder)
+		orders.GET("/:id", orderController.GetOrder)
+		orders.GET("/:id/invoice", orderController.DownloadInvoice)
+		orders.POST("/:id/status", orderController.UpdateOrderStatus)
+	}
+
+	// История заказов в профиле
+	profile := router.Group("/api/profile")
+	profile.Use(middleware.AuthMiddleware())
+	{
+		profile.GET("/orders", orderController.GetMyOrders)
+	}
+
+	// Управление всеми заказами для администраторов
+	admin := router.Group("/api/admin/orders")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("", orderController.ListOrders)
+		admin.GET("/:id", orderController.GetOrder)
+		admin.GET("/:id/invoice", orderController.DownloadInvoice)
+		admin.POST("/:id/status", orderController.UpdateOrderStatus)
+	}
+}
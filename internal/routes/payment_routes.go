@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPaymentRoutes configures order payments and provider webhooks
+func SetupPaymentRoutes(router *gin.Engine, paymentController *controllers.PaymentController) {
+	// Оплата заказа покупателем
+	orders := router.Group("/api/orders")
+	orders.Use(middleware.AuthMiddleware())
+	{
+		orders.POST("/:id/payments", paymentController.StartPayment)
+		orders.GET("/:id/payments", paymentController.GetOrderPayments)
+	}
+
+	// Уведомления платёжного провайдера; подлинность проверяется подписью, а не токеном
+	router.POST("/api/payments/webhooks/:provider", paymentController.HandleWebhook)
+
+	// Платежи и возвраты для администраторов
+	admin := router.Group("/api/admin/payments")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("", paymentController.ListPayments)
+		admin.POST("/:id/refund", paymentController.RefundPayment)
+	}
+}
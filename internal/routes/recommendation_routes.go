@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRecommendationRoutes configures the personalized recommendation feed
+func SetupRecommendationRoutes(router *gin.Engine, recommendationController *controllers.RecommendationController) {
+	recommendations := router.Group("/api/recommendations")
+	recommendations.Use(middleware.AuthMiddleware())
+	{
+		recommendations.GET("", recommendationController.GetRecommendations)
+	}
+}
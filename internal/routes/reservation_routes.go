@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupReservationRoutes configures all routes for car reservations
+func SetupReservationRoutes(router *gin.Engine, reservationController *controllers.ReservationController) {
+	// Бронирование машины со страницы автомобиля
+	cars := router.Group("/cars").Use(middleware.AuthMiddleware())
+	{
+		cars.POST("/:id/reservations", reservationController.ReserveCar)
+	}
+
+	// Брони текущего пользователя
+	reservations := router.Group("/api/reservations")
+	reservations.Use(middleware.AuthMiddleware())
+	{
+		reservations.GET("", reservationController.GetMyReservations)
+		reservations.POST("/:id/cancel", reservationController.CancelReservation)
+	}
+
+	// Управление бронями для администраторов
+	admin := router.Group("/api/admin/reservations")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("", reservationController.ListReservations)
+	}
+}
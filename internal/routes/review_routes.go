@@ -28,7 +28,7 @@ func SetupReviewRoutes(router *gin.Engine, reviewController *controllers.ReviewC
 	authReviews := router.Group("/reviews").Use(middleware.AuthMiddleware())
 	{
 		authReviews.POST("", reviewController.CreateReview)
-		authReviews.PUT("/:id", reviewController.UpdateReview)
-		authReviews.DELETE("/:id", reviewController.DeleteReview)
+		authReviews.PUT("/:id", middleware.RequireIfMatch(), reviewController.UpdateReview)
+		authReviews.DELETE("/:id", middleware.RequireIfMatch(), reviewController.DeleteReview)
 	}
 }
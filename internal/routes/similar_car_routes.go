@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSimilarCarRoutes configures the "similar cars" block of the car detail page
+func SetupSimilarCarRoutes(router *gin.Engine, similarCarsController *controllers.SimilarCarsController) {
+	router.GET("/cars/:id/similar", similarCarsController.GetSimilarCars)
+}
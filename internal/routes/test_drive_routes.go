@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+	"Cars/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTestDriveRoutes configures test drive slots, bookings and availability management
+func SetupTestDriveRoutes(router *gin.Engine, testDriveController *controllers.TestDriveController) {
+	router.GET("/cars/:id/test-drive-slots", testDriveController.GetSlots)
+
+	// Запись на тест-драйв со страницы автомобиля
+	cars := router.Group("/cars").Use(middleware.AuthMiddleware())
+	{
+		cars.POST("/:id/test-drives", testDriveController.BookTestDrive)
+	}
+
+	// Тест-драйвы текущего пользователя
+	testDrives := router.Group("/api/test-drives")
+	testDrives.Use(middleware.AuthMiddleware())
+	{
+		testDrives.GET("", testDriveController.GetMyTestDrives)
+		testDrives.POST("/:id/reschedule", testDriveController.RescheduleTestDrive)
+		testDrives.POST("/:id/cancel", testDriveController.CancelTestDrive)
+	}
+
+	// Расписание и записи для администраторов
+	admin := router.Group("/api/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.RoleMiddleware("ADMIN", "SUPER_ADMIN"))
+	{
+		admin.GET("/test-drives", testDriveController.ListTestDrives)
+		admin.GET("/test-drive-availability", testDriveController.ListAvailability)
+		admin.POST("/test-drive-availability", testDriveController.CreateAvailability)
+		admin.DELETE("/test-drive-availability/:id", testDriveController.DeleteAvailability)
+		admin.GET("/test-drive-blackouts", testDriveController.ListBlackouts)
+		admin.POST("/test-drive-blackouts", testDriveController.CreateBlackout)
+		admin.DELETE("/test-drive-blackouts/:id", testDriveController.DeleteBlackout)
+	}
+}
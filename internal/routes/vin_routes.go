@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"Cars/internal/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupVINRoutes configures the offline VIN decoder
+func SetupVINRoutes(router *gin.Engine) {
+	router.GET("/vin/:vin/decode", controllers.DecodeVIN)
+}
@@ -3,50 +3,460 @@ package services
 import (
 	"Cars/internal/models"
 	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Pagination defaults for the car catalog
+const (
+	DefaultCarsPerPage = 20
+	MaxCarsPerPage     = 100
+)
+
+// ErrInvalidCarQuery is returned when the catalog query string cannot be parsed
+var ErrInvalidCarQuery = errors.New("invalid car query")
+
+// ErrInvalidCar wraps validation errors of car data
+var ErrInvalidCar = errors.New("invalid car")
+
+type carFilterKind int
+
+const (
+	filterText carFilterKind = iota
+	filterNumber
+	filterInt
+	filterBool
+	filterStatus
+)
+
+// carFilterField describes a whitelisted catalog filter
+type carFilterField struct {
+	column string
+	op     string
+	kind   carFilterKind
+}
+
+// carFilterFields lists every query parameter that can be used to filter cars
+var carFilterFields = map[string]carFilterField{
+	"brand":          {column: "brand", op: "=", kind: filterText},
+	"model":          {column: "model", op: "=", kind: filterText},
+	"car_type":       {column: "car_type", op: "=", kind: filterText},
+	"transmission":   {column: "transmission", op: "=", kind: filterText},
+	"is_new":         {column: "is_new", op: "=", kind: filterBool},
+	"price_min":      {column: "price", op: ">=", kind: filterNumber},
+	"price_max":      {column: "price", op: "<=", kind: filterNumber},
+	"year_from":      {column: "year", op: ">=", kind: filterInt},
+	"year_to":        {column: "year", op: "<=", kind: filterInt},
+	"mileage_max":    {column: "mileage", op: "<=", kind: filterNumber},
+	"engine_vol_min": {column: "engine_volume", op: ">=", kind: filterNumber},
+	"engine_vol_max": {column: "engine_volume", op: "<=", kind: filterNumber},
+	"rating_min":     {column: "avg_rating", op: ">=", kind: filterNumber},
+	"status":         {column: "status", op: "=", kind: filterStatus},
+}
+
+// carSortFields maps sortable query names to their columns
+var carSortFields = map[string]string{
+	"id":         "id",
+	"brand":      "brand",
+	"model":      "model",
+	"car_type":   "car_type",
+	"year":       "year",
+	"mileage":    "mileage",
+	"engine_vol": "engine_volume",
+	"price":      "price",
+	"avg_rating": "avg_rating",
+}
+
+// carQueryReserved are non-filter parameters accepted by the catalog
+var carQueryReserved = map[string]bool{
+	"sort":     true,
+	"page":     true,
+	"per_page": true,
+	"currency": true, // resolved by the caller together with the Accept-Currency header
+}
+
+// CarFilter is a single parsed catalog condition
+type CarFilter struct {
+	Column string
+	Op     string
+	Value  interface{}
+	Text   bool
+}
+
+// CarSort is a single parsed sort key
+type CarSort struct {
+	Column string
+	Desc   bool
+}
+
+// CarQuery holds the parsed filters, sort order and page of a catalog request.
+// Price bounds are in Currency, or in the base currency when it is nil.
+type CarQuery struct {
+	Filters  []CarFilter
+	Sort     []CarSort
+	Page     int
+	PerPage  int
+	Currency *models.ExchangeRate
+}
+
+// HasFilter reports whether the query already filters on the given column
+func (q *CarQuery) HasFilter(column string) bool {
+	for _, f := range q.Filters {
+		if f.Column == column {
+			return true
+		}
+	}
+	return false
+}
+
+// Offset returns the number of rows to skip for the requested page
+func (q *CarQuery) Offset() int {
+	return (q.Page - 1) * q.PerPage
+}
+
+// ParseCarQuery validates catalog query parameters against the whitelisted car fields
+func ParseCarQuery(values url.Values) (*CarQuery, error) {
+	q := &CarQuery{Page: 1, PerPage: DefaultCarsPerPage}
+
+	for key, vals := range values {
+		if carQueryReserved[key] {
+			continue
+		}
+		field, ok := carFilterFields[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown filter %q", ErrInvalidCarQuery, key)
+		}
+		raw := strings.TrimSpace(vals[len(vals)-1])
+		if raw == "" {
+			continue
+		}
+
+		filter := CarFilter{Column: field.column, Op: field.op}
+		switch field.kind {
+		case filterText:
+			filter.Value = raw
+			filter.Text = true
+		case filterNumber:
+			v, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be a number", ErrInvalidCarQuery, key)
+			}
+			filter.Value = v
+		case filterInt:
+			v, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be an integer", ErrInvalidCarQuery, key)
+			}
+			filter.Value = v
+		case filterBool:
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be true or false", ErrInvalidCarQuery, key)
+			}
+			filter.Value = v
+		case filterStatus:
+			status := models.CarStatus(strings.ToLower(raw))
+			if !status.IsValid() {
+				return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidCarQuery, raw)
+			}
+			filter.Value = status
+		}
+		q.Filters = append(q.Filters, filter)
+	}
+
+	// Проданные машины скрыты, пока статус не запрошен явно
+	if !q.HasFilter("status") {
+		q.Filters = append(q.Filters, CarFilter{Column: "status", Op: "<>", Value: models.StatusSold})
+	}
+
+	if raw := values.Get("sort"); raw != "" {
+		seen := make(map[string]bool)
+		for _, key := range strings.Split(raw, ",") {
+			key = strings.TrimSpace(key)
+			desc := strings.HasPrefix(key, "-")
+			key = strings.TrimPrefix(strings.TrimPrefix(key, "-"), "+")
+			column, ok := carSortFields[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown sort field %q", ErrInvalidCarQuery, key)
+			}
+			if seen[column] {
+				continue
+			}
+			seen[column] = true
+			q.Sort = append(q.Sort, CarSort{Column: column, Desc: desc})
+		}
+	}
+
+	page, perPage, err := ParsePage(values)
+	if err != nil {
+		return nil, err
+	}
+	q.Page, q.PerPage = page, perPage
+
+	return q, nil
+}
+
+// ParsePage reads the page and per_page parameters shared by paginated car endpoints
+func ParsePage(values url.Values) (int, int, error) {
+	page, perPage := 1, DefaultCarsPerPage
+
+	if raw := values.Get("page"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 {
+			return 0, 0, fmt.Errorf("%w: page must be a positive integer", ErrInvalidCarQuery)
+		}
+		page = v
+	}
+
+	if raw := values.Get("per_page"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 1 || v > MaxCarsPerPage {
+			return 0, 0, fmt.Errorf("%w: per_page must be between 1 and %d", ErrInvalidCarQuery, MaxCarsPerPage)
+		}
+		perPage = v
+	}
+
+	return page, perPage, nil
+}
+
+// applyCarFilters adds the WHERE conditions of a catalog query; prices are compared across currencies
+func applyCarFilters(db *gorm.DB, q *CarQuery) *gorm.DB {
+	for _, f := range q.Filters {
+		if f.Column == "price" {
+			sql, args := carPriceFilterSQL(f.Op, f.Value.(float64), q.Currency)
+			db = db.Where(sql, args...)
+			continue
+		}
+		if f.Text {
+			db = db.Where(fmt.Sprintf("LOWER(%s) %s LOWER(?)", f.Column, f.Op), f.Value)
+			continue
+		}
+		db = db.Where(fmt.Sprintf("%s %s ?", f.Column, f.Op), f.Value)
+	}
+	return db
+}
+
+// applyCarSort adds the ORDER BY clause of a catalog query, always ending with id for stable pages.
+// Prices are sorted by their value in the base currency.
+func applyCarSort(db *gorm.DB, q *CarQuery) *gorm.DB {
+	hasID := false
+	for _, s := range q.Sort {
+		dir := "ASC"
+		if s.Desc {
+			dir = "DESC"
+		}
+		column := s.Column
+		if column == "price" {
+			column = carBasePriceSQL
+		}
+		db = db.Order(column + " " + dir)
+		if s.Column == "id" {
+			hasID = true
+		}
+	}
+	if !hasID {
+		db = db.Order("id ASC")
+	}
+	return db
+}
+
+// ListCars returns a page of cars matching the query along with the total number of matches
+func ListCars(q *CarQuery) ([]models.Car, int64, error) {
+	var total int64
+	if err := applyCarFilters(DB.Model(&models.Car{}), q).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	cars := []models.Car{}
+	if total == 0 {
+		return cars, 0, nil
+	}
+
+	err := applyCarSort(applyCarFilters(DB.Model(&models.Car{}), q), q).
+		Preload("Owner").
+		Offset(q.Offset()).
+		Limit(q.PerPage).
+		Find(&cars).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := ConvertCarPrices(cars, q.Currency); err != nil {
+		return nil, 0, err
+	}
+
+	return cars, total, nil
+}
+
 func GetCars() []models.Car {
 	var cars []models.Car
 	DB.Find(&cars)
 	return cars
 }
 
-func CreateCar(car models.Car) {
-	DB.Create(&car)
+// CreateCar validates a new car, fills and cross-checks brand and year from its VIN,
+// links it to the reference catalogs and starts its price history
+func CreateCar(car *models.Car, actorID uint) error {
+	// Новая машина всегда начинает жизненный цикл со статуса available
+	car.Status = models.StatusAvailable
+	car.PreviousPrice, car.PriceChangedAt = nil, nil
+	car.Version = 1
+
+	car.NormalizeSpecs()
+	prefillFromVIN(car)
+	if err := car.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCar, err)
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := resolveCarReferences(tx, car); err != nil {
+			return err
+		}
+		if err := checkCarVIN(tx, car); err != nil {
+			return err
+		}
+		if err := checkCarCurrency(tx, car); err != nil {
+			return err
+		}
+		if err := tx.Create(car).Error; err != nil {
+			return err
+		}
+		return recordPriceChangeTx(tx, car.ID, nil, car.Price, "", car.Currency, &actorID, models.PriceSourceCreate)
+	})
+}
+
+// carEditableColumns are the columns written by ReplaceCar and PatchCar.
+// Status, price tracking, rating and version are maintained by their own services.
+var carEditableColumns = []string{
+	"brand", "brand_id", "model", "car_model_id", "car_type", "car_type_id", "year", "image_url",
+	"mileage", "transmission", "engine_volume", "is_new", "fuel_type", "drivetrain", "color",
+	"doors", "seats", "horsepower", "vin", "description", "currency",
 }
 
-func UpdateCar(id string, car models.Car) {
-	DB.Model(&models.Car{}).Where("id = ?", id).Updates(car)
+// ReplaceCar replaces every editable field of a car with the values of car; omitted fields are cleared.
+// The stored version must be allowed by match. It returns the persisted car.
+func ReplaceCar(id string, car models.Car, editor CarEditor, match VersionMatch) (*models.Car, error) {
+	return updateCar(id, editor, match, func(current *models.Car) (*models.Car, error) {
+		return &car, nil
+	})
 }
 
-func DeleteCar(id string) error {
-	// Проверяем существование машины
-	var car models.Car
-	if err := DB.First(&car, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("car not found")
+// updateCar locks a car, checks that editor may change it, builds its new state with next and saves it with saveCarTx
+func updateCar(id string, editor CarEditor, match VersionMatch, next func(current *models.Car) (*models.Car, error)) (*models.Car, error) {
+	var updated models.Car
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var current models.Car
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
 		}
-		return err
+		if !editor.canManage(&current) {
+			return ErrCarForbidden
+		}
+		if !match.Allows(current.Version) {
+			return ErrVersionMismatch
+		}
+
+		car, err := next(&current)
+		if err != nil {
+			return err
+		}
+		if err := saveCarTx(tx, &current, car, editor.UserID); err != nil {
+			return err
+		}
+
+		return tx.Preload("Owner").First(&updated, current.ID).Error
+	})
+	if err != nil {
+		return nil, err
 	}
+	return &updated, nil
+}
 
-	// Удаляем машину
-	result := DB.Delete(&car)
-	if result.Error != nil {
-		return result.Error
+// saveCarTx validates car as the new state of current, links it to the reference catalogs, checks its VIN,
+// writes every editable column, records a price change and increases the version
+func saveCarTx(tx *gorm.DB, current *models.Car, car *models.Car, actorID uint) error {
+	car.ID = current.ID
+	car.NormalizeSpecs()
+
+	if err := resolveCarReferences(tx, car); err != nil {
+		return err
+	}
+	if err := car.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCar, err)
+	}
+	if err := checkCarVIN(tx, car); err != nil {
+		return err
+	}
+	if err := checkCarCurrency(tx, car); err != nil {
+		return err
 	}
 
-	if result.RowsAffected == 0 {
-		return errors.New("failed to delete car")
+	oldPrice, oldCurrency := current.Price, current.Currency
+	if err := tx.Model(current).Select(carEditableColumns).Updates(car).Error; err != nil {
+		return err
+	}
+	if err := recordPriceChangeTx(tx, current.ID, &oldPrice, car.Price, oldCurrency, car.Currency, &actorID, models.PriceSourceUpdate); err != nil {
+		return err
 	}
+	return bumpCarVersionTx(tx, current.ID)
+}
+
+// DeleteCar moves a car to the trash if editor may manage it and its version is allowed by match.
+// Its reviews, favorites and images are kept so it can be restored; an active reservation, upcoming
+// test drives and open offers are cancelled. Cars held by an open order cannot be deleted.
+// The row is removed for good by the trash retention job.
+func DeleteCar(id string, editor CarEditor, match VersionMatch) error {
+	actorID := editor.UserID
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var car models.Car
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if !editor.canManage(&car) {
+			return ErrCarForbidden
+		}
+		if !match.Allows(car.Version) {
+			return ErrVersionMismatch
+		}
+		if open, err := hasOpenOrderTx(tx, car.ID); err != nil {
+			return err
+		} else if open {
+			return ErrCarHasOpenOrder
+		}
+
+		cancelled, err := cancelActiveReservationTx(tx, car.ID, &actorID)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			if err := releaseReservedCarTx(tx, car.ID, &actorID, "car deleted"); err != nil {
+				return err
+			}
+		}
+		if err := cancelCarTestDrivesTx(tx, car.ID, &actorID); err != nil {
+			return err
+		}
+		if err := closeCarOffersTx(tx, car.ID, 0, models.OfferRejected, models.OfferActionRejected, "car deleted"); err != nil {
+			return err
+		}
 
-	return nil
+		return tx.Delete(&car).Error
+	})
 }
 
 func GetCarByID(id string) (*models.Car, error) {
 	var car models.Car
-	result := DB.First(&car, id)
+	result := DB.Preload("Owner").First(&car, id)
 	if result.Error != nil {
 		return nil, result.Error
 	}
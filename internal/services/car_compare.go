@@ -0,0 +1,306 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultMaxCompareCars is the default number of cars that can be compared at once
+const DefaultMaxCompareCars = 4
+
+// MaxSavedComparisons caps the number of comparisons a user can keep
+const MaxSavedComparisons = 50
+
+// Comparison errors
+var (
+	ErrInvalidComparison  = errors.New("invalid comparison")
+	ErrComparisonNotFound = errors.New("comparison not found")
+	ErrTooManyComparisons = fmt.Errorf("at most %d comparisons can be saved", MaxSavedComparisons)
+)
+
+// Which value of a numeric attribute is the best one
+const (
+	BestLowest  = "lowest"
+	BestHighest = "highest"
+)
+
+// compareAttribute describes one aligned row of a comparison
+type compareAttribute struct {
+	field string
+	best  string // empty for attributes without a best value
+	money bool   // num is an amount in the car's currency
+	value func(car *models.Car) interface{}
+	num   func(car *models.Car) float64
+}
+
+// compared returns the number the best value is picked by; amounts are compared in the base currency
+func (a compareAttribute) compared(car *models.Car, rates map[string]float64) float64 {
+	if a.money {
+		return toBaseCurrency(a.num(car), car.Currency, rates)
+	}
+	return a.num(car)
+}
+
+// compareAttributes lists the compared car attributes in display order
+var compareAttributes = []compareAttribute{
+	{field: "brand", value: func(c *models.Car) interface{} { return c.Brand }},
+	{field: "model", value: func(c *models.Car) interface{} { return c.Model }},
+	{field: "car_type", value: func(c *models.Car) interface{} { return c.CarType }},
+	{field: "year", best: BestHighest, value: func(c *models.Car) interface{} { return c.Year }, num: func(c *models.Car) float64 { return float64(c.Year) }},
+	{field: "price", best: BestLowest, money: true, value: func(c *models.Car) interface{} { return c.Price }, num: func(c *models.Car) float64 { return c.Price }},
+	{field: "mileage", best: BestLowest, value: func(c *models.Car) interface{} { return c.Mileage }, num: func(c *models.Car) float64 { return c.Mileage }},
+	{field: "engine_vol", value: func(c *models.Car) interface{} { return c.EngineVolume }},
+	{field: "transmission", value: func(c *models.Car) interface{} { return c.Transmission }},
+	{field: "fuel_type", value: func(c *models.Car) interface{} { return c.FuelType }},
+	{field: "drivetrain", value: func(c *models.Car) interface{} { return c.Drivetrain }},
+	{field: "horsepower", best: BestHighest, value: func(c *models.Car) interface{} { return c.Horsepower }, num: func(c *models.Car) float64 { return float64(c.Horsepower) }},
+	{field: "doors", value: func(c *models.Car) interface{} { return c.Doors }},
+	{field: "seats", value: func(c *models.Car) interface{} { return c.Seats }},
+	{field: "color", value: func(c *models.Car) interface{} { return c.Color }},
+	{field: "is_new", value: func(c *models.Car) interface{} { return c.IsNew }},
+	{field: "status", value: func(c *models.Car) interface{} { return c.Status }},
+	{field: "avg_rating", best: BestHighest, value: func(c *models.Car) interface{} { return c.AvgRating }, num: func(c *models.Car) float64 { return float64(c.AvgRating) }},
+}
+
+// ComparedAttribute is one attribute aligned across the compared cars; Values follow the order of Cars
+type ComparedAttribute struct {
+	Field      string        `json:"field"`
+	Values     []interface{} `json:"values"`
+	Differs    bool          `json:"differs"`
+	Best       string        `json:"best,omitempty"`
+	BestValue  interface{}   `json:"best_value,omitempty"`
+	BestCarIDs []uint        `json:"best_car_ids,omitempty"`
+}
+
+// ComparedCar is a compared car together with its rating statistics
+type ComparedCar struct {
+	Car         models.Car   `json:"car"`
+	RatingStats *RatingStats `json:"rating_stats"`
+}
+
+// CarComparison is the result of comparing cars side by side
+type CarComparison struct {
+	Cars          []ComparedCar       `json:"cars"`
+	Attributes    []ComparedAttribute `json:"attributes"`
+	MissingCarIDs []uint              `json:"missing_car_ids,omitempty"`
+}
+
+type CarCompareService struct {
+	db      *gorm.DB
+	reviews *ReviewService
+	maxCars int
+}
+
+func NewCarCompareService(db *gorm.DB, reviewService *ReviewService, maxCars int) *CarCompareService {
+	if maxCars < 2 {
+		maxCars = DefaultMaxCompareCars
+	}
+	return &CarCompareService{db: db, reviews: reviewService, maxCars: maxCars}
+}
+
+// MaxCars returns the maximum number of cars in one comparison
+func (s *CarCompareService) MaxCars() int {
+	return s.maxCars
+}
+
+// ParseCompareIDs reads a comma-separated list of car IDs, dropping duplicates
+func (s *CarCompareService) ParseCompareIDs(raw string) ([]uint, error) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%w: invalid car ID %q", ErrInvalidComparison, part)
+		}
+		if !seen[uint(id)] {
+			seen[uint(id)] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, s.checkIDs(ids)
+}
+
+func (s *CarCompareService) checkIDs(ids []uint) error {
+	if len(ids) < 2 || len(ids) > s.maxCars {
+		return fmt.Errorf("%w: between 2 and %d different cars are required", ErrInvalidComparison, s.maxCars)
+	}
+	return nil
+}
+
+// CompareCars compares the given cars; every car must exist
+func (s *CarCompareService) CompareCars(ids []uint) (*CarComparison, error) {
+	if err := s.checkIDs(ids); err != nil {
+		return nil, err
+	}
+	comparison, err := s.compare(ids)
+	if err != nil {
+		return nil, err
+	}
+	if len(comparison.MissingCarIDs) > 0 {
+		return nil, fmt.Errorf("%w: %v", ErrCarNotFound, comparison.MissingCarIDs)
+	}
+	return comparison, nil
+}
+
+// compare loads the cars in the requested order and aligns their attributes.
+// Cars that no longer exist are listed in MissingCarIDs.
+func (s *CarCompareService) compare(ids []uint) (*CarComparison, error) {
+	var found []models.Car
+	if err := s.db.Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Car, len(found))
+	for _, car := range found {
+		byID[car.ID] = car
+	}
+
+	comparison := &CarComparison{Cars: []ComparedCar{}, Attributes: []ComparedAttribute{}}
+	for _, id := range ids {
+		car, ok := byID[id]
+		if !ok {
+			comparison.MissingCarIDs = append(comparison.MissingCarIDs, id)
+			continue
+		}
+		stats, err := s.reviews.GetCarRatingStats(id)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Cars = append(comparison.Cars, ComparedCar{Car: car, RatingStats: stats})
+	}
+
+	if len(comparison.Cars) == 0 {
+		return comparison, nil
+	}
+
+	rates, err := exchangeRates(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attr := range compareAttributes {
+		row := ComparedAttribute{Field: attr.field, Best: attr.best, Values: make([]interface{}, len(comparison.Cars))}
+		for i := range comparison.Cars {
+			car := &comparison.Cars[i].Car
+			row.Values[i] = attr.value(car)
+			// Одинаковые суммы в разных валютах — это разные цены
+			if row.Values[i] != row.Values[0] || (attr.money && car.Currency != comparison.Cars[0].Car.Currency) {
+				row.Differs = true
+			}
+		}
+		if attr.best != "" {
+			row.BestValue, row.BestCarIDs = bestCompareValue(attr, comparison.Cars, rates)
+		}
+		comparison.Attributes = append(comparison.Attributes, row)
+	}
+
+	return comparison, nil
+}
+
+// bestCompareValue returns the best value of a numeric attribute and every car that has it
+func bestCompareValue(attr compareAttribute, cars []ComparedCar, rates map[string]float64) (interface{}, []uint) {
+	best := 0
+	for i := 1; i < len(cars); i++ {
+		v, b := attr.compared(&cars[i].Car, rates), attr.compared(&cars[best].Car, rates)
+		if (attr.best == BestLowest && v < b) || (attr.best == BestHighest && v > b) {
+			best = i
+		}
+	}
+
+	bestNum := attr.compared(&cars[best].Car, rates)
+	var ids []uint
+	for i := range cars {
+		if attr.compared(&cars[i].Car, rates) == bestNum {
+			ids = append(ids, cars[i].Car.ID)
+		}
+	}
+	return attr.value(&cars[best].Car), ids
+}
+
+// SaveComparison stores a comparison for the user
+func (s *CarCompareService) SaveComparison(userID uint, name string, carIDs []uint) (*models.SavedComparison, error) {
+	ids := make([]uint, 0, len(carIDs))
+	seen := make(map[uint]bool)
+	for _, id := range carIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if err := s.checkIDs(ids); err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if len(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be at most 100 characters", ErrInvalidComparison)
+	}
+
+	var existing int64
+	if err := s.db.Model(&models.Car{}).Where("id IN ?", ids).Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if int(existing) != len(ids) {
+		return nil, ErrCarNotFound
+	}
+
+	var count int64
+	if err := s.db.Model(&models.SavedComparison{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= MaxSavedComparisons {
+		return nil, ErrTooManyComparisons
+	}
+
+	saved := &models.SavedComparison{UserID: userID, Name: name, CarIDs: ids}
+	if err := s.db.Create(saved).Error; err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// GetSavedComparisons returns the user's saved comparisons, newest first
+func (s *CarCompareService) GetSavedComparisons(userID uint) ([]models.SavedComparison, error) {
+	saved := []models.SavedComparison{}
+	if err := s.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&saved).Error; err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+// OpenSavedComparison loads a saved comparison and compares its cars with their current data
+func (s *CarCompareService) OpenSavedComparison(userID, id uint) (*models.SavedComparison, *CarComparison, error) {
+	var saved models.SavedComparison
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&saved).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrComparisonNotFound
+		}
+		return nil, nil, err
+	}
+
+	comparison, err := s.compare(saved.CarIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &saved, comparison, nil
+}
+
+// DeleteSavedComparison removes a saved comparison of the user
+func (s *CarCompareService) DeleteSavedComparison(userID, id uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.SavedComparison{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrComparisonNotFound
+	}
+	return nil
+}
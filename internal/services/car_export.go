@@ -0,0 +1,185 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Export formats
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl"
+	ExportXLSX  = "xlsx"
+)
+
+// ErrInvalidExportFormat is returned for an unknown export format
+var ErrInvalidExportFormat = errors.New("export format must be csv, jsonl or xlsx")
+
+// ExportContentTypes maps export formats to their MIME types
+var ExportContentTypes = map[string]string{
+	ExportCSV:   "text/csv; charset=utf-8",
+	ExportJSONL: "application/x-ndjson",
+	ExportXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// carExportHeader lists the exported columns in order
+var carExportHeader = []string{
+	"id", "brand", "model", "car_type", "year", "mileage", "transmission",
+	"engine_vol", "price", "currency", "is_new", "status", "avg_rating", "image_url",
+	"fuel_type", "drivetrain", "color", "doors", "seats", "horsepower", "vin", "description",
+}
+
+// carRowWriter writes exported cars in a specific format
+type carRowWriter interface {
+	WriteCar(car *models.Car) error
+	Close() error
+}
+
+// ExportCars streams every car matching the query filters and sort order to w.
+// Rows are read one at a time with Rows(), so memory use stays constant regardless of catalog size.
+// Pagination parameters of the query are ignored.
+func ExportCars(q *CarQuery, format string, w io.Writer) error {
+	writer, err := newCarRowWriter(format, w)
+	if err != nil {
+		return err
+	}
+
+	rows, err := applyCarSort(applyCarFilters(DB.Model(&models.Car{}), q), q).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var car models.Car
+		if err := DB.ScanRows(rows, &car); err != nil {
+			return err
+		}
+		if err := writer.WriteCar(&car); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// newCarRowWriter creates a writer for the format and writes any header
+func newCarRowWriter(format string, w io.Writer) (carRowWriter, error) {
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(carExportHeader); err != nil {
+			return nil, err
+		}
+		return &csvCarWriter{w: cw}, nil
+	case ExportJSONL:
+		return &jsonlCarWriter{enc: json.NewEncoder(w)}, nil
+	case ExportXLSX:
+		xw, err := newXLSXWriter(w)
+		if err != nil {
+			return nil, err
+		}
+		header := make([]interface{}, len(carExportHeader))
+		for i, name := range carExportHeader {
+			header[i] = name
+		}
+		if err := xw.WriteRow(header...); err != nil {
+			return nil, err
+		}
+		return &xlsxCarWriter{w: xw}, nil
+	default:
+		return nil, ErrInvalidExportFormat
+	}
+}
+
+// carExportValues returns a car's exported values in header order
+func carExportValues(car *models.Car) []interface{} {
+	return []interface{}{
+		car.ID, car.Brand, car.Model, car.CarType, car.Year, car.Mileage, car.Transmission,
+		car.EngineVolume, car.Price, car.Currency, car.IsNew, string(car.Status), car.AvgRating, car.ImageURL,
+		string(car.FuelType), string(car.Drivetrain), car.Color, car.Doors, car.Seats, car.Horsepower, car.VIN, car.Description,
+	}
+}
+
+type csvCarWriter struct {
+	w     *csv.Writer
+	count int
+}
+
+func (c *csvCarWriter) WriteCar(car *models.Car) error {
+	values := carExportValues(car)
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch val := v.(type) {
+		case string:
+			record[i] = csvText(val)
+		case uint:
+			record[i] = strconv.FormatUint(uint64(val), 10)
+		case int:
+			record[i] = strconv.Itoa(val)
+		case float32:
+			record[i] = strconv.FormatFloat(float64(val), 'f', -1, 32)
+		case float64:
+			record[i] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			record[i] = strconv.FormatBool(val)
+		}
+	}
+	if err := c.w.Write(record); err != nil {
+		return err
+	}
+
+	// Периодически сбрасываем буфер, чтобы данные уходили клиенту по мере чтения
+	c.count++
+	if c.count%500 == 0 {
+		c.w.Flush()
+		return c.w.Error()
+	}
+	return nil
+}
+
+func (c *csvCarWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// csvText prefixes text that a spreadsheet would read as a formula with an apostrophe,
+// so an exported description such as "=HYPERLINK(...)" is shown as text when the file is opened
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+type jsonlCarWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlCarWriter) WriteCar(car *models.Car) error {
+	return j.enc.Encode(car)
+}
+
+func (j *jsonlCarWriter) Close() error {
+	return nil
+}
+
+type xlsxCarWriter struct {
+	w *xlsxWriter
+}
+
+func (x *xlsxCarWriter) WriteCar(car *models.Car) error {
+	return x.w.WriteRow(carExportValues(car)...)
+}
+
+func (x *xlsxCarWriter) Close() error {
+	return x.w.Close()
+}
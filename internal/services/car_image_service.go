@@ -0,0 +1,440 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	_ "image/gif"
+	_ "image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	"gorm.io/gorm"
+)
+
+// UploadsDir is the directory where uploaded files are stored and served from
+var UploadsDir = "uploads"
+
+// UploadsURLPrefix is the URL path under which UploadsDir is served
+const UploadsURLPrefix = "/uploads"
+
+// Gallery limits
+const (
+	MaxCarImageSize       = 10 << 20 // 10 MB per file
+	MaxCarImagesPerUpload = 10
+	maxCarImagePixels     = 40_000_000
+	mediumImageSize       = 1024
+	thumbnailImageSize    = 256
+	renditionJPEGQuality  = 85
+)
+
+// allowedImageTypes lists the MIME types accepted for car images, detected from file contents
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Car image errors
+var (
+	ErrCarImageNotFound     = errors.New("car image not found")
+	ErrNoImagesUploaded     = errors.New("no images uploaded")
+	ErrTooManyImages        = fmt.Errorf("at most %d images can be uploaded at once", MaxCarImagesPerUpload)
+	ErrImageTooLarge        = fmt.Errorf("image exceeds the %d MB limit", MaxCarImageSize>>20)
+	ErrUnsupportedImageType = errors.New("unsupported image type, allowed: jpeg, png, gif")
+	ErrInvalidImage         = errors.New("image could not be decoded")
+	ErrInvalidImageOrder    = errors.New("image order must list every image of the car exactly once")
+)
+
+// CarImageService manages car galleries stored on the local filesystem
+type CarImageService struct {
+	db *gorm.DB
+}
+
+// NewCarImageService creates a new instance of CarImageService
+func NewCarImageService(db *gorm.DB) *CarImageService {
+	return &CarImageService{db: db}
+}
+
+// preparedImage is an uploaded file that passed validation and is ready to be stored
+type preparedImage struct {
+	data     []byte
+	mimeType string
+	ext      string
+	img      image.Image
+}
+
+// UploadImages validates, stores and registers uploaded images for a car.
+// Every file is checked before anything is written, so one bad file rejects the whole upload;
+// files are then decoded and stored one at a time to keep at most one decoded image in memory.
+func (s *CarImageService) UploadImages(carID uint, files []*multipart.FileHeader) ([]models.CarImage, error) {
+	if len(files) == 0 {
+		return nil, ErrNoImagesUploaded
+	}
+	if len(files) > MaxCarImagesPerUpload {
+		return nil, ErrTooManyImages
+	}
+
+	var car models.Car
+	if err := s.db.Select("id").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	for _, fh := range files {
+		if _, err := readImage(fh); err != nil {
+			return nil, fmt.Errorf("%s: %w", fh.Filename, err)
+		}
+	}
+
+	var written []string
+	images := make([]models.CarImage, 0, len(files))
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		var maxPosition int
+		if err := tx.Model(&models.CarImage{}).Where("car_id = ?", carID).Count(&count).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.CarImage{}).Where("car_id = ?", carID).
+			Select("COALESCE(MAX(position), 0)").Scan(&maxPosition).Error; err != nil {
+			return err
+		}
+
+		for i, fh := range files {
+			p, err := prepareImage(fh)
+			if err != nil {
+				return fmt.Errorf("%s: %w", fh.Filename, err)
+			}
+			stored, err := storeImageFiles(carID, p)
+			written = append(written, stored...)
+			if err != nil {
+				return err
+			}
+
+			bounds := p.img.Bounds()
+			carImage := models.CarImage{
+				CarID:         carID,
+				Position:      maxPosition + i + 1,
+				IsPrimary:     count == 0 && i == 0,
+				MimeType:      p.mimeType,
+				Size:          int64(len(p.data)),
+				Width:         bounds.Dx(),
+				Height:        bounds.Dy(),
+				OriginalPath:  stored[0],
+				MediumPath:    stored[1],
+				ThumbnailPath: stored[2],
+			}
+			if err := tx.Create(&carImage).Error; err != nil {
+				return err
+			}
+			images = append(images, carImage)
+		}
+
+		return syncCarImageURL(tx, carID)
+	})
+	if err != nil {
+		// Файлы без записей в базе не нужны
+		for _, file := range written {
+			os.Remove(filepath.Join(UploadsDir, file))
+		}
+		return nil, err
+	}
+
+	for i := range images {
+		withImageURLs(&images[i])
+	}
+	return images, nil
+}
+
+// GetCarImages returns the gallery of a car in display order
+func (s *CarImageService) GetCarImages(carID uint) ([]models.CarImage, error) {
+	var car models.Car
+	if err := s.db.Select("id").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	images := []models.CarImage{}
+	if err := s.db.Where("car_id = ?", carID).Order("position ASC, id ASC").Find(&images).Error; err != nil {
+		return nil, err
+	}
+	for i := range images {
+		withImageURLs(&images[i])
+	}
+	return images, nil
+}
+
+// DeleteImage removes an image and its files; if it was primary the next image takes its place
+func (s *CarImageService) DeleteImage(carID, imageID uint) error {
+	var carImage models.CarImage
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND car_id = ?", imageID, carID).First(&carImage).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarImageNotFound
+			}
+			return err
+		}
+
+		if err := tx.Delete(&carImage).Error; err != nil {
+			return err
+		}
+
+		if carImage.IsPrimary {
+			var next models.CarImage
+			err := tx.Where("car_id = ?", carID).Order("position ASC, id ASC").First(&next).Error
+			if err == nil {
+				if err := tx.Model(&next).Update("is_primary", true).Error; err != nil {
+					return err
+				}
+			} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		return syncCarImageURL(tx, carID)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range []string{carImage.OriginalPath, carImage.MediumPath, carImage.ThumbnailPath} {
+		os.Remove(filepath.Join(UploadsDir, file))
+	}
+	return nil
+}
+
+// SetPrimaryImage marks one image of the car as primary
+func (s *CarImageService) SetPrimaryImage(carID, imageID uint) ([]models.CarImage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var carImage models.CarImage
+		if err := tx.Where("id = ? AND car_id = ?", imageID, carID).First(&carImage).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarImageNotFound
+			}
+			return err
+		}
+
+		if err := tx.Model(&models.CarImage{}).Where("car_id = ?", carID).Update("is_primary", false).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&carImage).Update("is_primary", true).Error; err != nil {
+			return err
+		}
+
+		return syncCarImageURL(tx, carID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCarImages(carID)
+}
+
+// ReorderImages sets the display order of a car's gallery
+func (s *CarImageService) ReorderImages(carID uint, imageIDs []uint) ([]models.CarImage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		if err := tx.Model(&models.CarImage{}).Where("car_id = ?", carID).Pluck("id", &existing).Error; err != nil {
+			return err
+		}
+
+		if len(existing) != len(imageIDs) {
+			return ErrInvalidImageOrder
+		}
+		known := make(map[uint]bool, len(existing))
+		for _, id := range existing {
+			known[id] = true
+		}
+		for _, id := range imageIDs {
+			if !known[id] {
+				return ErrInvalidImageOrder
+			}
+			delete(known, id)
+		}
+
+		for i, id := range imageIDs {
+			if err := tx.Model(&models.CarImage{}).Where("id = ?", id).Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCarImages(carID)
+}
+
+// removeCarImageFiles deletes every stored file of a car's gallery
+func removeCarImageFiles(carID uint) error {
+	return os.RemoveAll(filepath.Join(UploadsDir, carImageDir(carID)))
+}
+
+// carImageDir returns the gallery directory of a car relative to UploadsDir
+func carImageDir(carID uint) string {
+	return path.Join("cars", strconv.FormatUint(uint64(carID), 10))
+}
+
+// withImageURLs fills the public URLs of an image from its stored paths
+func withImageURLs(carImage *models.CarImage) {
+	carImage.OriginalURL = UploadsURLPrefix + "/" + carImage.OriginalPath
+	carImage.MediumURL = UploadsURLPrefix + "/" + carImage.MediumPath
+	carImage.ThumbnailURL = UploadsURLPrefix + "/" + carImage.ThumbnailPath
+}
+
+// syncCarImageURL keeps cars.image_url pointing at the medium rendition of the primary image.
+// The car's version is bumped with it, so an ETag taken before a gallery change no longer matches.
+func syncCarImageURL(tx *gorm.DB, carID uint) error {
+	var primary models.CarImage
+	imageURL := ""
+	err := tx.Where("car_id = ? AND is_primary = ?", carID, true).First(&primary).Error
+	if err == nil {
+		withImageURLs(&primary)
+		imageURL = primary.MediumURL
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return tx.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
+		"image_url": imageURL,
+		"version":   gorm.Expr("version + 1"),
+	}).Error
+}
+
+// prepareImage reads and checks an uploaded file with readImage and decodes it
+func prepareImage(fh *multipart.FileHeader) (preparedImage, error) {
+	p, err := readImage(fh)
+	if err != nil {
+		return preparedImage{}, err
+	}
+	p.img, _, err = image.Decode(bytes.NewReader(p.data))
+	if err != nil {
+		return preparedImage{}, ErrInvalidImage
+	}
+	return p, nil
+}
+
+// readImage reads an uploaded file, sniffs its real type and checks its dimensions without decoding the pixels
+func readImage(fh *multipart.FileHeader) (preparedImage, error) {
+	if fh.Size > MaxCarImageSize {
+		return preparedImage{}, ErrImageTooLarge
+	}
+
+	file, err := fh.Open()
+	if err != nil {
+		return preparedImage{}, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxCarImageSize+1))
+	if err != nil {
+		return preparedImage{}, err
+	}
+	if len(data) > MaxCarImageSize {
+		return preparedImage{}, ErrImageTooLarge
+	}
+
+	// Тип определяем по содержимому файла, а не по заголовкам клиента
+	mt := mimetype.Detect(data)
+	if !allowedImageTypes[mt.String()] {
+		return preparedImage{}, ErrUnsupportedImageType
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxCarImagePixels {
+		return preparedImage{}, ErrInvalidImage
+	}
+
+	return preparedImage{data: data, mimeType: mt.String(), ext: mt.Extension()}, nil
+}
+
+// storeImageFiles writes the original and its medium and thumbnail renditions.
+// It returns the paths relative to UploadsDir in that order, including any written before a failure.
+func storeImageFiles(carID uint, p preparedImage) ([]string, error) {
+	dir := carImageDir(carID)
+	if err := os.MkdirAll(filepath.Join(UploadsDir, dir), 0o755); err != nil {
+		return nil, err
+	}
+
+	name, err := randomFileName()
+	if err != nil {
+		return nil, err
+	}
+
+	original := path.Join(dir, name+"_original"+p.ext)
+	medium := path.Join(dir, name+"_medium.jpg")
+	thumbnail := path.Join(dir, name+"_thumb.jpg")
+
+	var written []string
+	if err := os.WriteFile(filepath.Join(UploadsDir, original), p.data, 0o644); err != nil {
+		return written, err
+	}
+	written = append(written, original)
+
+	for _, r := range []struct {
+		file string
+		size int
+	}{{medium, mediumImageSize}, {thumbnail, thumbnailImageSize}} {
+		if err := writeRendition(filepath.Join(UploadsDir, r.file), p.img, r.size); err != nil {
+			return written, err
+		}
+		written = append(written, r.file)
+	}
+
+	return written, nil
+}
+
+// writeRendition scales the image to fit within size×size and saves it as JPEG
+func writeRendition(file string, src image.Image, size int) error {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w > size || h > size {
+		if w >= h {
+			h = max(1, h*size/w)
+			w = size
+		} else {
+			w = max(1, w*size/h)
+			h = size
+		}
+	}
+
+	// JPEG не поддерживает прозрачность, поэтому подкладываем белый фон
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+
+	out, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	if err := jpeg.Encode(out, dst, &jpeg.Options{Quality: renditionJPEGQuality}); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// randomFileName returns a random hex name for a stored file
+func randomFileName() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
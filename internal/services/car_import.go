@@ -0,0 +1,337 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MaxImportRows caps the number of cars accepted by a single import
+const MaxImportRows = 5000
+
+// Import modes
+const (
+	ImportAtomic     = "atomic"      // all rows are written or none
+	ImportBestEffort = "best_effort" // valid rows are written, invalid ones are skipped
+)
+
+// Row statuses in an import report
+const (
+	ImportRowCreated  = "created"
+	ImportRowValid    = "valid" // passed validation but was not written (dry run or aborted atomic import)
+	ImportRowRejected = "rejected"
+)
+
+// Import errors
+var (
+	ErrInvalidImport     = errors.New("invalid import")
+	ErrInvalidImportMode = errors.New("import mode must be atomic or best_effort")
+)
+
+// carImportColumns maps CSV header names to the fields they fill
+var carImportColumns = map[string]func(car *models.Car, value string) error{
+	"brand":        func(car *models.Car, v string) error { car.Brand = v; return nil },
+	"model":        func(car *models.Car, v string) error { car.Model = v; return nil },
+	"car_type":     func(car *models.Car, v string) error { car.CarType = v; return nil },
+	"image_url":    func(car *models.Car, v string) error { car.ImageURL = v; return nil },
+	"transmission": func(car *models.Car, v string) error { car.Transmission = v; return nil },
+	"year": func(car *models.Car, v string) (err error) {
+		car.Year, err = parseImportInt(v)
+		return err
+	},
+	"mileage": func(car *models.Car, v string) (err error) {
+		car.Mileage, err = parseImportFloat(v)
+		return err
+	},
+	"engine_vol": func(car *models.Car, v string) (err error) {
+		car.EngineVolume, err = parseImportFloat(v)
+		return err
+	},
+	"price": func(car *models.Car, v string) (err error) {
+		car.Price, err = parseImportFloat(v)
+		return err
+	},
+	"currency": func(car *models.Car, v string) error { car.Currency = v; return nil },
+	"is_new": func(car *models.Car, v string) (err error) {
+		if v == "" {
+			return nil
+		}
+		car.IsNew, err = strconv.ParseBool(v)
+		return err
+	},
+	"fuel_type":   func(car *models.Car, v string) error { car.FuelType = models.FuelType(v); return nil },
+	"drivetrain":  func(car *models.Car, v string) error { car.Drivetrain = models.Drivetrain(v); return nil },
+	"color":       func(car *models.Car, v string) error { car.Color = v; return nil },
+	"vin":         func(car *models.Car, v string) error { car.VIN = v; return nil },
+	"description": func(car *models.Car, v string) error { car.Description = v; return nil },
+	"doors": func(car *models.Car, v string) (err error) {
+		car.Doors, err = parseImportInt(v)
+		return err
+	},
+	"seats": func(car *models.Car, v string) (err error) {
+		car.Seats, err = parseImportInt(v)
+		return err
+	},
+	"horsepower": func(car *models.Car, v string) (err error) {
+		car.Horsepower, err = parseImportInt(v)
+		return err
+	},
+}
+
+// CarImportRow is one parsed input row; ParseErrors are set when the row could not be read
+type CarImportRow struct {
+	Row         int
+	Car         models.Car
+	ParseErrors []string
+}
+
+// CarImportOptions controls how an import is applied
+type CarImportOptions struct {
+	Mode    string
+	DryRun  bool
+	ActorID uint
+}
+
+// CarImportRowResult is the outcome of a single row
+type CarImportRowResult struct {
+	Row    int      `json:"row"`
+	Status string   `json:"status"`
+	CarID  uint     `json:"car_id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// CarImportReport summarizes an import
+type CarImportReport struct {
+	Mode      string               `json:"mode"`
+	DryRun    bool                 `json:"dry_run"`
+	Committed bool                 `json:"committed"`
+	Total     int                  `json:"total"`
+	Created   int                  `json:"created"`
+	Rejected  int                  `json:"rejected"`
+	Rows      []CarImportRowResult `json:"rows"`
+}
+
+// ParseCarCSV reads cars from CSV with a header row naming car fields
+func ParseCarCSV(r io.Reader) ([]CarImportRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: CSV is empty", ErrInvalidImport)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+	}
+
+	columns := make([]string, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := carImportColumns[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidImport, name)
+		}
+		columns[i] = name
+	}
+
+	var rows []CarImportRow
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("%w: at most %d rows can be imported at once", ErrInvalidImport, MaxImportRows)
+		}
+
+		row := CarImportRow{Row: line}
+		if err != nil {
+			row.ParseErrors = append(row.ParseErrors, err.Error())
+			rows = append(rows, row)
+			continue
+		}
+		if len(record) != len(columns) {
+			row.ParseErrors = append(row.ParseErrors, fmt.Sprintf("expected %d fields, got %d", len(columns), len(record)))
+			rows = append(rows, row)
+			continue
+		}
+
+		for i, value := range record {
+			if err := carImportColumns[columns[i]](&row.Car, strings.TrimSpace(value)); err != nil {
+				row.ParseErrors = append(row.ParseErrors, fmt.Sprintf("%s: invalid value %q", columns[i], value))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// ParseCarJSON reads cars from a JSON array of car objects
+func ParseCarJSON(r io.Reader) ([]CarImportRow, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("%w: body must be a JSON array of cars", ErrInvalidImport)
+	}
+	if len(items) > MaxImportRows {
+		return nil, fmt.Errorf("%w: at most %d rows can be imported at once", ErrInvalidImport, MaxImportRows)
+	}
+
+	rows := make([]CarImportRow, len(items))
+	for i, item := range items {
+		rows[i].Row = i + 1
+		decoder := json.NewDecoder(bytes.NewReader(item))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&rows[i].Car); err != nil {
+			rows[i].ParseErrors = append(rows[i].ParseErrors, err.Error())
+		}
+	}
+
+	return rows, nil
+}
+
+// ImportCars validates every row with Car.Validate, checks its brand, model and car type against
+// the reference catalogs, cross-checks its VIN and writes the valid ones according to the options.
+// A VIN repeated within the same import is rejected like one that is already listed.
+func ImportCars(rows []CarImportRow, opts CarImportOptions) (*CarImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = ImportAtomic
+	}
+	if opts.Mode != ImportAtomic && opts.Mode != ImportBestEffort {
+		return nil, ErrInvalidImportMode
+	}
+
+	report := &CarImportReport{
+		Mode:   opts.Mode,
+		DryRun: opts.DryRun,
+		Total:  len(rows),
+		Rows:   make([]CarImportRowResult, len(rows)),
+	}
+
+	seenVINs := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		result := &report.Rows[i]
+		result.Row = row.Row
+		result.Errors = row.ParseErrors
+		if len(result.Errors) == 0 {
+			prepareImportedCar(&row.Car)
+			if err := row.Car.Validate(); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else if err := resolveCarReferences(DB, &row.Car); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else if err := checkCarVIN(DB, &row.Car); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else if err := checkCarCurrency(DB, &row.Car); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			} else if first, ok := seenVINs[row.Car.VIN]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("%v: same VIN as row %d", ErrDuplicateVIN, first))
+			} else if row.Car.VIN != "" {
+				seenVINs[row.Car.VIN] = row.Row
+			}
+		}
+		if len(result.Errors) > 0 {
+			result.Status = ImportRowRejected
+			report.Rejected++
+			continue
+		}
+		result.Status = ImportRowValid
+	}
+
+	if opts.DryRun || report.Total == 0 {
+		return report, nil
+	}
+
+	if opts.Mode == ImportAtomic {
+		if report.Rejected > 0 {
+			return report, nil
+		}
+		// Все строки уже проверены, поэтому ошибка записи — сбой базы, а не отказ строки
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			for i := range rows {
+				if err := createImportedCarTx(tx, &rows[i].Car, opts.ActorID); err != nil {
+					return fmt.Errorf("row %d: %w", rows[i].Row, err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return report, err
+		}
+		for i := range rows {
+			report.Rows[i].Status = ImportRowCreated
+			report.Rows[i].CarID = rows[i].Car.ID
+		}
+		report.Created = len(rows)
+		report.Committed = true
+		return report, nil
+	}
+
+	// best_effort: каждая строка пишется в своей транзакции
+	for i := range rows {
+		if report.Rows[i].Status != ImportRowValid {
+			continue
+		}
+		err := DB.Transaction(func(tx *gorm.DB) error {
+			return createImportedCarTx(tx, &rows[i].Car, opts.ActorID)
+		})
+		if err != nil {
+			report.Rows[i].Status = ImportRowRejected
+			report.Rows[i].Errors = append(report.Rows[i].Errors, err.Error())
+			report.Rejected++
+			continue
+		}
+		report.Rows[i].Status = ImportRowCreated
+		report.Rows[i].CarID = rows[i].Car.ID
+		report.Created++
+	}
+	report.Committed = report.Created > 0
+
+	return report, nil
+}
+
+// createImportedCarTx writes an imported car and starts its price history
+func createImportedCarTx(tx *gorm.DB, car *models.Car, actorID uint) error {
+	if err := tx.Create(car).Error; err != nil {
+		return err
+	}
+	return recordPriceChangeTx(tx, car.ID, nil, car.Price, "", car.Currency, &actorID, models.PriceSourceImport)
+}
+
+// prepareImportedCar resets fields that imports must not control and fills brand and year from the VIN
+func prepareImportedCar(car *models.Car) {
+	car.ID = 0
+	car.Status = models.StatusAvailable
+	car.AvgRating = 0
+	car.Reviews = nil
+	car.PreviousPrice = nil
+	car.PriceChangedAt = nil
+	car.Version = 1
+	// Импортированные машины, как и созданные через POST /cars, принадлежат автосалону
+	car.OwnerID = nil
+	car.Owner = nil
+	car.NormalizeSpecs()
+	prefillFromVIN(car)
+}
+
+func parseImportInt(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(v)
+}
+
+func parseImportFloat(v string) (float64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(v, 64)
+}
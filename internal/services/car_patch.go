@@ -0,0 +1,105 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"encoding/json"
+	"fmt"
+)
+
+// carPatchFields are the JSON fields a merge patch may change, mapped to the reference
+// fields that must be looked up again when they change
+var carPatchFields = map[string][]string{
+	"brand":        {"brand_id", "car_model_id"},
+	"brand_id":     {"car_model_id"},
+	"model":        {"car_model_id"},
+	"car_model_id": nil,
+	"car_type":     {"car_type_id"},
+	"car_type_id":  nil,
+	"year":         nil,
+	"image_url":    nil,
+	"mileage":      nil,
+	"transmission": nil,
+	"engine_vol":   nil,
+	"price":        nil,
+	"currency":     nil,
+	"is_new":       nil,
+	"fuel_type":    nil,
+	"drivetrain":   nil,
+	"color":        nil,
+	"doors":        nil,
+	"seats":        nil,
+	"horsepower":   nil,
+	"vin":          nil,
+	"description":  nil,
+}
+
+// PatchCar applies an RFC 7396 JSON Merge Patch to a car: fields in the patch replace the stored values,
+// null clears a field and omitted fields are kept. The merged car is validated like a full replacement.
+// The stored version must be allowed by match. It returns the persisted car.
+func PatchCar(id string, patch []byte, editor CarEditor, match VersionMatch) (*models.Car, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(patch, &fields); err != nil || fields == nil {
+		return nil, fmt.Errorf("%w: merge patch must be a JSON object", ErrInvalidCar)
+	}
+	for name := range fields {
+		if _, ok := carPatchFields[name]; !ok {
+			return nil, fmt.Errorf("%w: field %q cannot be changed", ErrInvalidCar, name)
+		}
+	}
+
+	return updateCar(id, editor, match, func(current *models.Car) (*models.Car, error) {
+		doc, err := json.Marshal(current)
+		if err != nil {
+			return nil, err
+		}
+		var target interface{}
+		if err := json.Unmarshal(doc, &target); err != nil {
+			return nil, err
+		}
+		var patchDoc interface{}
+		if err := json.Unmarshal(patch, &patchDoc); err != nil {
+			return nil, err
+		}
+		target = mergePatch(target, patchDoc)
+
+		// Изменённое имя важнее сохранённой ссылки: ID сбрасываются и ищутся заново
+		obj := target.(map[string]interface{})
+		for name := range fields {
+			for _, ref := range carPatchFields[name] {
+				if _, set := fields[ref]; !set {
+					delete(obj, ref)
+				}
+			}
+		}
+
+		merged, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		var car models.Car
+		if err := json.Unmarshal(merged, &car); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCar, err)
+		}
+		return &car, nil
+	})
+}
+
+// mergePatch applies an RFC 7396 merge patch to a decoded JSON document
+func mergePatch(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for name, value := range patchObj {
+		if value == nil {
+			delete(targetObj, name)
+			continue
+		}
+		targetObj[name] = mergePatch(targetObj[name], value)
+	}
+	return targetObj
+}
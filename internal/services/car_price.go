@@ -0,0 +1,181 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxBulkPriceUpdates caps the number of cars changed by one bulk price update
+const MaxBulkPriceUpdates = 1000
+
+// ErrInvalidPriceUpdate is returned for a malformed bulk price update
+var ErrInvalidPriceUpdate = errors.New("invalid price update")
+
+// CarPriceChange sets the price of a single car
+type CarPriceChange struct {
+	CarID uint    `json:"car_id"`
+	Price float64 `json:"price"`
+}
+
+// BulkPriceUpdate either sets explicit prices or changes the prices of CarIDs by Percent
+type BulkPriceUpdate struct {
+	Prices  []CarPriceChange `json:"prices"`
+	CarIDs  []uint           `json:"car_ids"`
+	Percent float64          `json:"percent"`
+}
+
+// recordPriceChangeTx writes a car_price_history entry and, when the price actually changed,
+// stores the new price on the car along with the old one so the drop can be shown without reading the history.
+// oldPrice is nil for the first price of a new car. A change of currency starts the series afresh:
+// prices in different currencies are not compared, so it shows no drop and triggers no alerts.
+// Price alerts of users who favorited the car are evaluated here.
+func recordPriceChangeTx(tx *gorm.DB, carID uint, oldPrice *float64, newPrice float64, oldCurrency, newCurrency string, actorID *uint, source string) error {
+	recurrency := oldPrice != nil && oldCurrency != newCurrency
+	if recurrency {
+		oldPrice = nil
+	} else if oldPrice != nil && *oldPrice == newPrice {
+		return nil
+	}
+
+	entry := models.CarPriceHistory{
+		CarID:     carID,
+		OldPrice:  oldPrice,
+		NewPrice:  newPrice,
+		Currency:  newCurrency,
+		ChangedBy: actorID,
+		Source:    source,
+	}
+	if err := tx.Create(&entry).Error; err != nil {
+		return err
+	}
+
+	if recurrency {
+		// Прежняя цена в другой валюте несравнима с новой, бейдж снижения снимаем
+		err := tx.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
+			"price":            newPrice,
+			"previous_price":   nil,
+			"price_changed_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		// Цена последнего уведомления была в старой валюте, поэтому below_target взводится заново
+		return tx.Model(&models.Favorite{}).Where("car_id = ? AND alert_notified_price IS NOT NULL", carID).
+			Update("alert_notified_price", nil).Error
+	}
+	if oldPrice == nil {
+		return nil
+	}
+	err := tx.Model(&models.Car{}).Where("id = ?", carID).Updates(map[string]interface{}{
+		"price":            newPrice,
+		"previous_price":   *oldPrice,
+		"price_changed_at": entry.CreatedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	return evaluatePriceAlertsTx(tx, carID, *oldPrice, newPrice)
+}
+
+// GetCarPriceHistory returns the price time series of a car, oldest first
+func GetCarPriceHistory(carID uint) ([]models.CarPriceHistory, error) {
+	var car models.Car
+	if err := DB.Select("id").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	history := []models.CarPriceHistory{}
+	if err := DB.Where("car_id = ?", carID).Order("created_at ASC, id ASC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// BulkUpdatePrices applies a bulk price update atomically and returns the updated cars
+func BulkUpdatePrices(update BulkPriceUpdate, actorID uint) ([]models.Car, error) {
+	changes, err := update.changes()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+
+	var cars []models.Car
+	err = DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", ids).Order("id").Find(&cars).Error; err != nil {
+			return err
+		}
+		if len(cars) != len(ids) {
+			return ErrCarNotFound
+		}
+
+		for _, car := range cars {
+			oldPrice := car.Price
+			newPrice := changes[car.ID](oldPrice)
+			if newPrice <= 0 {
+				return fmt.Errorf("%w: car %d would get a non-positive price", ErrInvalidPriceUpdate, car.ID)
+			}
+			if err := recordPriceChangeTx(tx, car.ID, &oldPrice, newPrice, car.Currency, car.Currency, &actorID, models.PriceSourceBulk); err != nil {
+				return err
+			}
+			if newPrice != oldPrice {
+				if err := bumpCarVersionTx(tx, car.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.Where("id IN ?", ids).Order("id").Find(&cars).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cars, nil
+}
+
+// changes validates the update and returns a price function per car
+func (u BulkPriceUpdate) changes() (map[uint]func(float64) float64, error) {
+	if (len(u.Prices) > 0) == (len(u.CarIDs) > 0) {
+		return nil, fmt.Errorf("%w: provide either prices or car_ids with percent", ErrInvalidPriceUpdate)
+	}
+	if len(u.Prices)+len(u.CarIDs) > MaxBulkPriceUpdates {
+		return nil, fmt.Errorf("%w: at most %d cars can be updated at once", ErrInvalidPriceUpdate, MaxBulkPriceUpdates)
+	}
+
+	changes := make(map[uint]func(float64) float64)
+	for _, p := range u.Prices {
+		if p.Price <= 0 {
+			return nil, fmt.Errorf("%w: price of car %d must be positive", ErrInvalidPriceUpdate, p.CarID)
+		}
+		if _, dup := changes[p.CarID]; dup {
+			return nil, fmt.Errorf("%w: car %d is listed twice", ErrInvalidPriceUpdate, p.CarID)
+		}
+		price := p.Price
+		changes[p.CarID] = func(float64) float64 { return price }
+	}
+
+	if len(u.CarIDs) > 0 {
+		if u.Percent == 0 || u.Percent <= -100 {
+			return nil, fmt.Errorf("%w: percent must be non-zero and greater than -100", ErrInvalidPriceUpdate)
+		}
+		factor := 1 + u.Percent/100
+		for _, id := range u.CarIDs {
+			changes[id] = func(old float64) float64 { return math.Round(old*factor*100) / 100 }
+		}
+	}
+
+	return changes, nil
+}
@@ -0,0 +1,333 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Reference catalog errors
+var (
+	ErrReferenceNotFound   = errors.New("reference not found")
+	ErrReferenceExists     = errors.New("reference with this name already exists")
+	ErrReferenceInUse      = errors.New("reference is still in use")
+	ErrInvalidReference    = errors.New("reference name must be between 1 and 100 characters")
+	ErrUnknownCarReference = errors.New("unknown car reference")
+)
+
+// CarReferenceService manages the brand, model and car type catalogs
+type CarReferenceService struct {
+	db *gorm.DB
+}
+
+func NewCarReferenceService(db *gorm.DB) *CarReferenceService {
+	return &CarReferenceService{db: db}
+}
+
+// ListBrands returns every brand ordered by name
+func (s *CarReferenceService) ListBrands() ([]models.Brand, error) {
+	brands := []models.Brand{}
+	err := s.db.Order("LOWER(name), id").Find(&brands).Error
+	return brands, err
+}
+
+// CreateBrand adds a brand to the catalog
+func (s *CarReferenceService) CreateBrand(name string) (*models.Brand, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureUniqueReference(s.db, &models.Brand{}, 0, name, ""); err != nil {
+		return nil, err
+	}
+
+	brand := &models.Brand{Name: name}
+	if err := s.db.Create(brand).Error; err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
+// UpdateBrand renames a brand and the denormalized brand name of its cars
+func (s *CarReferenceService) UpdateBrand(id uint, name string) (*models.Brand, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var brand models.Brand
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findReference(tx, &brand, id); err != nil {
+			return err
+		}
+		if err := ensureUniqueReference(tx, &models.Brand{}, id, name, ""); err != nil {
+			return err
+		}
+		if err := tx.Model(&brand).Update("name", name).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Car{}).Where("brand_id = ?", id).Updates(map[string]interface{}{
+			"brand":   name,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &brand, nil
+}
+
+// DeleteBrand removes a brand that has no models and no cars
+func (s *CarReferenceService) DeleteBrand(id uint) error {
+	return s.deleteReference(&models.Brand{}, id, map[interface{}]string{
+		&models.CarModel{}: "brand_id = ?",
+		&models.Car{}:      "brand_id = ?",
+	})
+}
+
+// ListModels returns the models of a brand, or every model when brandID is 0
+func (s *CarReferenceService) ListModels(brandID uint) ([]models.CarModel, error) {
+	if brandID != 0 {
+		if err := findReference(s.db, &models.Brand{}, brandID); err != nil {
+			return nil, err
+		}
+	}
+
+	carModels := []models.CarModel{}
+	query := s.db.Order("LOWER(name), id")
+	if brandID != 0 {
+		query = query.Where("brand_id = ?", brandID)
+	}
+	err := query.Find(&carModels).Error
+	return carModels, err
+}
+
+// CreateModel adds a model to a brand
+func (s *CarReferenceService) CreateModel(brandID uint, name string) (*models.CarModel, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := findReference(s.db, &models.Brand{}, brandID); err != nil {
+		return nil, err
+	}
+	if err := ensureUniqueReference(s.db, &models.CarModel{}, 0, name, "brand_id = ?", brandID); err != nil {
+		return nil, err
+	}
+
+	carModel := &models.CarModel{BrandID: brandID, Name: name}
+	if err := s.db.Create(carModel).Error; err != nil {
+		return nil, err
+	}
+	return carModel, nil
+}
+
+// UpdateModel renames a model and the denormalized model name of its cars
+func (s *CarReferenceService) UpdateModel(id uint, name string) (*models.CarModel, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var carModel models.CarModel
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findReference(tx, &carModel, id); err != nil {
+			return err
+		}
+		if err := ensureUniqueReference(tx, &models.CarModel{}, id, name, "brand_id = ?", carModel.BrandID); err != nil {
+			return err
+		}
+		if err := tx.Model(&carModel).Update("name", name).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Car{}).Where("car_model_id = ?", id).Updates(map[string]interface{}{
+			"model":   name,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &carModel, nil
+}
+
+// DeleteModel removes a model that no car uses
+func (s *CarReferenceService) DeleteModel(id uint) error {
+	return s.deleteReference(&models.CarModel{}, id, map[interface{}]string{
+		&models.Car{}: "car_model_id = ?",
+	})
+}
+
+// ListCarTypes returns every car type ordered by name
+func (s *CarReferenceService) ListCarTypes() ([]models.CarType, error) {
+	types := []models.CarType{}
+	err := s.db.Order("LOWER(name), id").Find(&types).Error
+	return types, err
+}
+
+// CreateCarType adds a car type to the catalog
+func (s *CarReferenceService) CreateCarType(name string) (*models.CarType, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureUniqueReference(s.db, &models.CarType{}, 0, name, ""); err != nil {
+		return nil, err
+	}
+
+	carType := &models.CarType{Name: name}
+	if err := s.db.Create(carType).Error; err != nil {
+		return nil, err
+	}
+	return carType, nil
+}
+
+// UpdateCarType renames a car type and the denormalized type name of its cars
+func (s *CarReferenceService) UpdateCarType(id uint, name string) (*models.CarType, error) {
+	name, err := referenceName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var carType models.CarType
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findReference(tx, &carType, id); err != nil {
+			return err
+		}
+		if err := ensureUniqueReference(tx, &models.CarType{}, id, name, ""); err != nil {
+			return err
+		}
+		if err := tx.Model(&carType).Update("name", name).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&models.Car{}).Where("car_type_id = ?", id).Updates(map[string]interface{}{
+			"car_type": name,
+			"version":  gorm.Expr("version + 1"),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &carType, nil
+}
+
+// DeleteCarType removes a car type that no car uses
+func (s *CarReferenceService) DeleteCarType(id uint) error {
+	return s.deleteReference(&models.CarType{}, id, map[interface{}]string{
+		&models.Car{}: "car_type_id = ?",
+	})
+}
+
+// ensureUniqueReference checks that no other entry (with an ID other than id) has the same name in the given scope
+func ensureUniqueReference(db *gorm.DB, model interface{}, id uint, name string, scope string, scopeArgs ...interface{}) error {
+	query := db.Model(model).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id)
+	if scope != "" {
+		query = query.Where(scope, scopeArgs...)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrReferenceExists
+	}
+	return nil
+}
+
+// deleteReference deletes an entry unless one of the dependent tables still refers to it
+func (s *CarReferenceService) deleteReference(model interface{}, id uint, dependents map[interface{}]string) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := findReference(tx, model, id); err != nil {
+			return err
+		}
+		// Машины в корзине тоже держат ссылку, пока их не удалит очистка
+		for dependent, where := range dependents {
+			var count int64
+			if err := tx.Unscoped().Model(dependent).Where(where, id).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrReferenceInUse
+			}
+		}
+		return tx.Delete(model, id).Error
+	})
+}
+
+func findReference(db *gorm.DB, dest interface{}, id uint) error {
+	if err := db.First(dest, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrReferenceNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func referenceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return "", ErrInvalidReference
+	}
+	return name, nil
+}
+
+// resolveCarReferences links a car to the brand, model and car type catalogs.
+// A reference is looked up by its ID when set, otherwise by name (case-insensitive);
+// the car then gets the canonical names. Brand and model are required, the car type is optional.
+func resolveCarReferences(db *gorm.DB, car *models.Car) error {
+	var brand models.Brand
+	if err := lookupReference(db, &brand, car.BrandID, car.Brand, "", "brand"); err != nil {
+		return err
+	}
+	car.BrandID, car.Brand = &brand.ID, brand.Name
+
+	var carModel models.CarModel
+	if err := lookupReference(db, &carModel, car.CarModelID, car.Model, "brand_id = ?", "model", brand.ID); err != nil {
+		return err
+	}
+	if carModel.BrandID != brand.ID {
+		return fmt.Errorf("%w: model %q does not belong to brand %q", ErrUnknownCarReference, carModel.Name, brand.Name)
+	}
+	car.CarModelID, car.Model = &carModel.ID, carModel.Name
+
+	if car.CarTypeID == nil && strings.TrimSpace(car.CarType) == "" {
+		car.CarType = ""
+		return nil
+	}
+	var carType models.CarType
+	if err := lookupReference(db, &carType, car.CarTypeID, car.CarType, "", "car type"); err != nil {
+		return err
+	}
+	car.CarTypeID, car.CarType = &carType.ID, carType.Name
+
+	return nil
+}
+
+func lookupReference(db *gorm.DB, dest interface{}, id *uint, name string, scope string, kind string, scopeArgs ...interface{}) error {
+	var err error
+	if id != nil {
+		err = db.First(dest, *id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s %d does not exist", ErrUnknownCarReference, kind, *id)
+		}
+		return err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("%w: %s is required", ErrUnknownCarReference, kind)
+	}
+	query := db.Where("LOWER(name) = LOWER(?)", name)
+	if scope != "" {
+		query = query.Where(scope, scopeArgs...)
+	}
+	err = query.First(dest).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("%w: %s %q does not exist", ErrUnknownCarReference, kind, name)
+	}
+	return err
+}
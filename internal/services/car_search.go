@@ -0,0 +1,131 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// ErrEmptySearchQuery is returned when a search query contains no searchable terms
+var ErrEmptySearchQuery = errors.New("search query must contain at least one word")
+
+// maxSearchTerms caps the number of words taken from a search query
+const maxSearchTerms = 10
+
+var searchTermPattern = regexp.MustCompile(`[\p{L}\p{N}]+(?:\.[\p{N}]+)?`)
+
+// searchField is a car column taking part in relevance ranking
+type searchField struct {
+	column string
+	weight int
+}
+
+// searchFields mirrors the weights of the search_vector column on PostgreSQL
+var searchFields = []searchField{
+	{column: "brand", weight: 4},
+	{column: "model", weight: 4},
+	{column: "car_type", weight: 2},
+	{column: "transmission", weight: 1},
+}
+
+// SearchTerms splits a free-text query into lower-cased search terms
+func SearchTerms(q string) []string {
+	terms := searchTermPattern.FindAllString(strings.ToLower(q), -1)
+	seen := make(map[string]bool, len(terms))
+	result := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		result = append(result, term)
+		if len(result) == maxSearchTerms {
+			break
+		}
+	}
+	return result
+}
+
+// SearchCars ranks cars by relevance to the query across brand, model, car type and transmission.
+// Any matching term is enough for a car to be included; cars matching more terms rank higher.
+func SearchCars(q string, page, perPage int) ([]models.Car, int64, error) {
+	terms := SearchTerms(q)
+	if len(terms) == 0 {
+		return nil, 0, ErrEmptySearchQuery
+	}
+
+	var where, rank string
+	var whereArgs, rankArgs []interface{}
+	if DB.Dialector.Name() == "postgres" {
+		where, whereArgs, rank, rankArgs = postgresSearchClauses(terms)
+	} else {
+		where, whereArgs, rank, rankArgs = likeSearchClauses(terms)
+	}
+
+	// Проданные машины в поиске не показываем, как и в каталоге
+	where = "status <> ? AND " + where
+	whereArgs = append([]interface{}{models.StatusSold}, whereArgs...)
+
+	var total int64
+	if err := DB.Model(&models.Car{}).Where(where, whereArgs...).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	cars := []models.Car{}
+	if total == 0 {
+		return cars, 0, nil
+	}
+
+	err := DB.Model(&models.Car{}).
+		Where(where, whereArgs...).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: rank + " DESC, id ASC", Vars: rankArgs, WithoutParentheses: true}}).
+		Preload("Owner").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&cars).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return cars, total, nil
+}
+
+// postgresSearchClauses builds the tsvector match and ts_rank expressions.
+// Terms only contain letters, digits and dots, so they are safe to quote inside the tsquery.
+func postgresSearchClauses(terms []string) (string, []interface{}, string, []interface{}) {
+	parts := make([]string, len(terms))
+	for i, term := range terms {
+		parts[i] = fmt.Sprintf("'%s':*", term)
+	}
+	tsquery := strings.Join(parts, " | ")
+
+	return "search_vector @@ to_tsquery('simple', ?)", []interface{}{tsquery},
+		"ts_rank(search_vector, to_tsquery('simple', ?))", []interface{}{tsquery}
+}
+
+// likeSearchClauses builds a portable LIKE-based match and a weighted score per matching field
+func likeSearchClauses(terms []string) (string, []interface{}, string, []interface{}) {
+	var conditions, scores []string
+	var whereArgs, rankArgs []interface{}
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		for _, f := range searchFields {
+			conditions = append(conditions, fmt.Sprintf("LOWER(%s) LIKE ? ESCAPE '\\'", f.column))
+			whereArgs = append(whereArgs, pattern)
+			scores = append(scores, fmt.Sprintf("CASE WHEN LOWER(%s) LIKE ? ESCAPE '\\' THEN %d ELSE 0 END", f.column, f.weight))
+			rankArgs = append(rankArgs, pattern)
+		}
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", whereArgs,
+		"(" + strings.Join(scores, " + ") + ")", rankArgs
+}
+
+// escapeLike escapes LIKE wildcards in user input
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
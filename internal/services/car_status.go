@@ -0,0 +1,109 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrCarNotFound is returned when a car does not exist
+var ErrCarNotFound = errors.New("car not found")
+
+// ChangeCarStatus moves a car to a new status and records the change in car_status_history.
+// actorID is nil for changes made by the system; admin allows reverting a sold car.
+// Taking a car out of reservation cancels its active reservation, and moving it to maintenance or sold
+// cancels its upcoming test drives. Cars with an open order are moved only by the order,
+// so their status cannot be changed here.
+func ChangeCarStatus(carID uint, to models.CarStatus, actorID *uint, reason string, admin bool) (*models.Car, error) {
+	var car *models.Car
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		car, err = changeCarStatusTx(tx, carID, to, actorID, reason, admin)
+		if err != nil {
+			return err
+		}
+		// Машину с открытым заказом ведёт только заказ; проверка идёт после блокировки строки машины
+		if open, err := hasOpenOrderTx(tx, carID); err != nil {
+			return err
+		} else if open {
+			return ErrCarHasOpenOrder
+		}
+		// Машина больше не в резерве — активная бронь отменяется, иначе следующая бронь упрётся в неё
+		if to != models.StatusReservation {
+			if _, err := cancelActiveReservationTx(tx, carID, actorID); err != nil {
+				return err
+			}
+		}
+		// На машину в ремонте или проданную тест-драйвы не записывают, уже записанные отменяются
+		if !testDriveAllowed(to) {
+			return cancelCarTestDrivesTx(tx, carID, actorID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return car, nil
+}
+
+// changeCarStatusTx performs a status change inside an existing transaction.
+// The car row is locked so concurrent transitions are applied one after another.
+func changeCarStatusTx(tx *gorm.DB, carID uint, to models.CarStatus, actorID *uint, reason string, admin bool) (*models.Car, error) {
+	var car models.Car
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	from := car.Status
+	if from == "" {
+		from = models.StatusAvailable
+	}
+	if err := from.CanTransitionTo(to, admin); err != nil {
+		return nil, err
+	}
+
+	err := tx.Model(&car).Updates(map[string]interface{}{
+		"status":  to,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	history := models.CarStatusHistory{
+		CarID:      car.ID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  actorID,
+		Reason:     reason,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return nil, err
+	}
+
+	car.Status = to
+	car.Version++
+	return &car, nil
+}
+
+// GetCarStatusHistory returns the status changes of a car, newest first
+func GetCarStatusHistory(carID uint) ([]models.CarStatusHistory, error) {
+	var car models.Car
+	if err := DB.Select("id").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
+	history := []models.CarStatusHistory{}
+	if err := DB.Where("car_id = ?", carID).Order("created_at DESC, id DESC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+	return history, nil
+}
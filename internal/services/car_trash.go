@@ -0,0 +1,177 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultTrashRetention is how long deleted cars stay in the trash unless configured otherwise
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// ErrCarNotDeleted is returned when restoring a car that is not in the trash
+var ErrCarNotDeleted = errors.New("car is not in the trash")
+
+// TrashedCar is a deleted car together with the time it will be purged
+type TrashedCar struct {
+	models.Car
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
+
+// CarTrashService lists, restores and purges soft-deleted cars
+type CarTrashService struct {
+	db        *gorm.DB
+	retention time.Duration
+}
+
+// NewCarTrashService creates a new instance of CarTrashService with the given retention period
+func NewCarTrashService(db *gorm.DB, retention time.Duration) *CarTrashService {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	return &CarTrashService{db: db, retention: retention}
+}
+
+// Retention returns how long deleted cars are kept
+func (s *CarTrashService) Retention() time.Duration {
+	return s.retention
+}
+
+// ListTrash returns a page of deleted cars, most recently deleted first
+func (s *CarTrashService) ListTrash(page, perPage int) ([]TrashedCar, int64, error) {
+	query := s.db.Unscoped().Model(&models.Car{}).Where("deleted_at IS NOT NULL")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	trashed := []TrashedCar{}
+	if total == 0 {
+		return trashed, 0, nil
+	}
+
+	var cars []models.Car
+	err := query.Order("deleted_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&cars).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, car := range cars {
+		trashed = append(trashed, TrashedCar{
+			Car:       car,
+			DeletedAt: car.DeletedAt.Time,
+			PurgeAt:   car.DeletedAt.Time.Add(s.retention),
+		})
+	}
+	return trashed, total, nil
+}
+
+// RestoreCar takes a car out of the trash with its reviews, favorites and images.
+// A car whose VIN has since been listed again cannot be restored.
+func (s *CarTrashService) RestoreCar(id uint) (*models.Car, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var car models.Car
+		if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if !car.DeletedAt.Valid {
+			return ErrCarNotDeleted
+		}
+		if car.VIN != "" {
+			if err := ensureUniqueVIN(tx, car.VIN, car.ID); err != nil {
+				return err
+			}
+		}
+		return tx.Unscoped().Model(&car).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var car models.Car
+	if err := s.db.First(&car, id).Error; err != nil {
+		return nil, err
+	}
+	return &car, nil
+}
+
+// PurgeExpired permanently deletes cars that have been in the trash longer than the retention period,
+// together with their gallery files. Cars that were ordered stay in the trash for the order history.
+// A car that fails to purge is logged and skipped, so one bad row does not hold up the rest;
+// it returns the number of purged cars together with the joined errors of the skipped ones.
+func (s *CarTrashService) PurgeExpired(now time.Time) (int, error) {
+	var ids []uint
+	err := s.db.Unscoped().Model(&models.Car{}).
+		Where("deleted_at IS NOT NULL AND deleted_at <= ?", now.Add(-s.retention)).
+		Where("NOT EXISTS (SELECT 1 FROM orders WHERE orders.car_id = cars.id)").
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	var errs []error
+	for _, id := range ids {
+		// Отзывы, изображения и история удаляются каскадом; у избранного, броней, тест-драйвов, переписок и предложений каскада нет
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			dependents := []interface{}{
+				&models.Favorite{}, &models.Reservation{},
+				&models.TestDrive{}, &models.TestDriveAvailability{}, &models.TestDriveBlackout{},
+				&models.MessageThread{}, &models.Offer{},
+			}
+			for _, dependent := range dependents {
+				if err := tx.Where("car_id = ?", id).Delete(dependent).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Unscoped().Delete(&models.Car{}, id).Error
+		})
+		if err != nil {
+			log.Printf("failed to purge car %d: %v", id, err)
+			errs = append(errs, fmt.Errorf("car %d: %w", id, err))
+			continue
+		}
+		purged++
+
+		if err := removeCarImageFiles(id); err != nil {
+			log.Printf("failed to remove images of car %d: %v", id, err)
+		}
+	}
+
+	return purged, errors.Join(errs...)
+}
+
+// StartPurgeWorker periodically purges expired cars from the trash until the context is cancelled
+func (s *CarTrashService) StartPurgeWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.PurgeExpired(time.Now())
+			if err != nil {
+				log.Printf("car trash purge failed: %v", err)
+			}
+			if count > 0 {
+				log.Printf("purged %d car(s) from the trash", count)
+			}
+		}
+	}
+}
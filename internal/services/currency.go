@@ -0,0 +1,129 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// carBasePriceSQL is the price of a car converted to the base currency
+const carBasePriceSQL = "(cars.price * (SELECT exchange_rates.rate FROM exchange_rates WHERE exchange_rates.currency = cars.currency))"
+
+// ResolveCurrency looks up the exchange rate of a requested currency; an empty code means no conversion
+func ResolveCurrency(code string) (*models.ExchangeRate, error) {
+	code = models.NormalizeCurrency(code)
+	if code == "" {
+		return nil, nil
+	}
+	if !models.IsCurrencyCode(code) {
+		return nil, models.ErrInvalidCurrency
+	}
+	var rate models.ExchangeRate
+	if err := DB.First(&rate, "currency = ?", code).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, code)
+		}
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// ConvertCarPrices fills the converted price of every car in the requested currency, rounded by its rule.
+// Nothing is done when no currency was requested.
+func ConvertCarPrices(cars []models.Car, to *models.ExchangeRate) error {
+	if to == nil || len(cars) == 0 {
+		return nil
+	}
+
+	byCurrency, err := exchangeRates(DB)
+	if err != nil {
+		return err
+	}
+
+	for i := range cars {
+		// Цена в валюте без курса остаётся без пересчёта
+		if from, ok := byCurrency[cars[i].Currency]; ok {
+			setConvertedPrice(&cars[i], from, to)
+		}
+	}
+	return nil
+}
+
+// ConvertCarPrice fills the converted price of a single car, like ConvertCarPrices
+func ConvertCarPrice(car *models.Car, to *models.ExchangeRate) error {
+	if to == nil {
+		return nil
+	}
+	var from models.ExchangeRate
+	if err := DB.First(&from, "currency = ?", car.Currency).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	setConvertedPrice(car, from.Rate, to)
+	return nil
+}
+
+// exchangeRates returns the value in the base currency of one unit of every currency with a rate
+func exchangeRates(db *gorm.DB) (map[string]float64, error) {
+	var rates []models.ExchangeRate
+	if err := db.Find(&rates).Error; err != nil {
+		return nil, err
+	}
+	byCurrency := make(map[string]float64, len(rates))
+	for _, rate := range rates {
+		byCurrency[rate.Currency] = rate.Rate
+	}
+	return byCurrency, nil
+}
+
+// currencyRule returns the exchange rate of a currency for its rounding rule.
+// A currency without a rate is rounded to cents.
+func currencyRule(db *gorm.DB, currency string) (*models.ExchangeRate, error) {
+	rule := models.ExchangeRate{Currency: currency, Decimals: 2}
+	if err := db.Where("currency = ?", currency).Limit(1).Find(&rule).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// toBaseCurrency converts an amount to the base currency for comparisons with prices in other currencies.
+// Amounts in a currency without a rate are kept as they are.
+func toBaseCurrency(amount float64, currency string, rates map[string]float64) float64 {
+	if rate, ok := rates[currency]; ok {
+		return amount * rate
+	}
+	return amount
+}
+
+// setConvertedPrice converts the price of a car worth fromRate in the base currency per unit
+func setConvertedPrice(car *models.Car, fromRate float64, to *models.ExchangeRate) {
+	converted := to.Round(car.Price * fromRate / to.Rate)
+	car.ConvertedPrice = &converted
+	car.ConvertedCurrency = to.Currency
+}
+
+// carPriceFilterSQL compares the price of a car with a bound given in the requested currency.
+// The converted price is rounded like displayed prices, so the filter agrees with converted_price.
+func carPriceFilterSQL(op string, bound float64, currency *models.ExchangeRate) (string, []interface{}) {
+	if currency == nil {
+		return fmt.Sprintf("%s %s ?", carBasePriceSQL, op), []interface{}{bound}
+	}
+	return fmt.Sprintf("ROUND(CAST(%s / ? AS NUMERIC), ?) %s ?", carBasePriceSQL, op),
+		[]interface{}{currency.Rate, currency.Decimals, bound}
+}
+
+// checkCarCurrency makes sure a car is priced in a currency with an exchange rate
+func checkCarCurrency(db *gorm.DB, car *models.Car) error {
+	var count int64
+	if err := db.Model(&models.ExchangeRate{}).Where("currency = ?", car.Currency).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, car.Currency)
+	}
+	return nil
+}
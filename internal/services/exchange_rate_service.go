@@ -0,0 +1,99 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Exchange rate errors
+var (
+	ErrUnsupportedCurrency  = errors.New("no exchange rate is defined for the currency")
+	ErrExchangeRateNotFound = errors.New("exchange rate not found")
+	ErrBaseCurrencyRate     = errors.New("the base currency cannot be removed")
+	ErrCurrencyInUse        = errors.New("the currency is still used by cars")
+)
+
+// ExchangeRateService maintains the exchange rate table. Rates are entered by admins; there is no live feed.
+type ExchangeRateService struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateService creates a new instance of ExchangeRateService
+func NewExchangeRateService(db *gorm.DB) *ExchangeRateService {
+	return &ExchangeRateService{db: db}
+}
+
+// ListRates returns every exchange rate ordered by currency
+func (s *ExchangeRateService) ListRates() ([]models.ExchangeRate, error) {
+	rates := []models.ExchangeRate{}
+	err := s.db.Order("currency").Find(&rates).Error
+	return rates, err
+}
+
+// SetRate creates or replaces the rate of a currency. A nil decimals keeps the current
+// rounding rule, or 2 decimals for a new currency.
+func (s *ExchangeRateService) SetRate(currency string, rate float64, decimals *int, actorID uint) (*models.ExchangeRate, error) {
+	var saved models.ExchangeRate
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		entry := models.ExchangeRate{Currency: models.NormalizeCurrency(currency), Rate: rate, Decimals: 2, UpdatedBy: &actorID}
+
+		var current models.ExchangeRate
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "currency = ?", entry.Currency).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			entry.Decimals = current.Decimals
+		}
+		if decimals != nil {
+			entry.Decimals = *decimals
+		}
+		if err := entry.Validate(); err != nil {
+			return err
+		}
+
+		// Upsert: новая валюта добавляется, существующая перезаписывается
+		err = tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "currency"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate", "decimals", "updated_by", "updated_at"}),
+		}).Create(&entry).Error
+		if err != nil {
+			return err
+		}
+		return tx.First(&saved, "currency = ?", entry.Currency).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// DeleteRate removes the rate of a currency that no car is priced in, including cars in the trash
+func (s *ExchangeRateService) DeleteRate(currency string) error {
+	currency = models.NormalizeCurrency(currency)
+	if currency == models.BaseCurrency {
+		return ErrBaseCurrencyRate
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Unscoped().Model(&models.Car{}).Where("currency = ?", currency).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrCurrencyInUse
+		}
+
+		result := tx.Delete(&models.ExchangeRate{}, "currency = ?", currency)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrExchangeRateNotFound
+		}
+		return nil
+	})
+}
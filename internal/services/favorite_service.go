@@ -7,6 +7,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrFavoriteNotFound is returned when the car is not in the user's favorites
+var ErrFavoriteNotFound = errors.New("favorite not found")
+
 type FavoriteService struct {
 	db *gorm.DB
 }
@@ -17,6 +20,15 @@ func NewFavoriteService(db *gorm.DB) *FavoriteService {
 
 // AddToFavorites добавляет автомобиль в избранное пользователя
 func (s *FavoriteService) AddToFavorites(userID, carID uint) (*models.Favorite, error) {
+	// Машины из корзины добавить нельзя
+	var car models.Car
+	if err := s.db.Select("id").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+
 	// Проверяем, существует ли уже такая запись
 	var existing models.Favorite
 	result := s.db.Where("user_id = ? AND car_id = ?", userID, carID).First(&existing)
@@ -42,15 +54,16 @@ func (s *FavoriteService) AddToFavorites(userID, carID uint) (*models.Favorite,
 func (s *FavoriteService) RemoveFromFavorites(userID, carID uint) error {
 	result := s.db.Where("user_id = ? AND car_id = ?", userID, carID).Delete(&models.Favorite{})
 	if result.RowsAffected == 0 {
-		return errors.New("favorite not found")
+		return ErrFavoriteNotFound
 	}
 	return nil
 }
 
-// GetUserFavorites получает список избранных автомобилей пользователя
+// GetUserFavorites получает список избранных автомобилей пользователя.
+// Машины в корзине скрыты, но запись остаётся и вернётся после восстановления.
 func (s *FavoriteService) GetUserFavorites(userID uint) ([]models.Favorite, error) {
 	var favorites []models.Favorite
-	if err := s.db.Where("user_id = ?", userID).Preload("Car").Find(&favorites).Error; err != nil {
+	if err := s.db.Where("user_id = ? AND car_id IN (?)", userID, s.activeCarIDs()).Preload("Car").Find(&favorites).Error; err != nil {
 		return nil, err
 	}
 	return favorites, nil
@@ -62,3 +75,81 @@ func (s *FavoriteService) IsFavorite(userID, carID uint) bool {
 	result := s.db.Where("user_id = ? AND car_id = ?", userID, carID).First(&favorite)
 	return result.Error == nil
 }
+
+// SetPriceAlert configures the price-drop alert of a favorite car
+func (s *FavoriteService) SetPriceAlert(userID, carID uint, alertType models.PriceAlertType, target *float64) (*models.Favorite, error) {
+	if err := models.ValidateAlert(alertType, target); err != nil {
+		return nil, err
+	}
+	if alertType == models.AlertAnyDrop {
+		target = nil
+	}
+
+	var favorite models.Favorite
+	if err := s.db.Where("user_id = ? AND car_id = ?", userID, carID).First(&favorite).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFavoriteNotFound
+		}
+		return nil, err
+	}
+
+	// Цель задаётся в текущей валюте машины и сравнивается с ценой по курсу
+	targetCurrency := ""
+	if target != nil {
+		var car models.Car
+		if err := s.db.Select("id", "currency").First(&car, carID).Error; err != nil {
+			return nil, err
+		}
+		targetCurrency = car.Currency
+	}
+
+	err := s.db.Model(&favorite).Updates(map[string]interface{}{
+		"alert_type":            alertType,
+		"alert_target_price":    target,
+		"alert_target_currency": targetCurrency,
+		"alert_notified_price":  nil,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.Preload("Car").First(&favorite, favorite.ID)
+	return &favorite, nil
+}
+
+// RemovePriceAlert turns off the price-drop alert of a favorite car
+func (s *FavoriteService) RemovePriceAlert(userID, carID uint) error {
+	result := s.db.Model(&models.Favorite{}).
+		Where("user_id = ? AND car_id = ?", userID, carID).
+		Updates(map[string]interface{}{
+			"alert_type":            "",
+			"alert_target_price":    nil,
+			"alert_target_currency": "",
+			"alert_notified_price":  nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrFavoriteNotFound
+	}
+	return nil
+}
+
+// GetPriceAlerts returns the user's favorites that have an alert configured
+func (s *FavoriteService) GetPriceAlerts(userID uint) ([]models.Favorite, error) {
+	favorites := []models.Favorite{}
+	err := s.db.Where("user_id = ? AND alert_type <> '' AND car_id IN (?)", userID, s.activeCarIDs()).
+		Preload("Car").
+		Order("created_at DESC").
+		Find(&favorites).Error
+	if err != nil {
+		return nil, err
+	}
+	return favorites, nil
+}
+
+// activeCarIDs is a subquery of the IDs of cars that are not in the trash
+func (s *FavoriteService) activeCarIDs() *gorm.DB {
+	return s.db.Model(&models.Car{}).Select("id")
+}
@@ -0,0 +1,345 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Financing errors
+var (
+	ErrInvalidFinancing         = errors.New("invalid financing request")
+	ErrFinancingProductExists   = errors.New("financing product with this name already exists")
+	ErrFinancingProductNotFound = errors.New("financing product not found")
+)
+
+// FinancingQuery holds the parameters of a financing calculation.
+// Either Rate or ProductID selects the interest rate.
+type FinancingQuery struct {
+	DownPayment float64
+	TermMonths  int
+	Rate        *float64 // annual rate in percent
+	ProductID   uint
+}
+
+// ParseFinancingQuery reads down_payment, term_months, rate and product_id from the query string
+func ParseFinancingQuery(values url.Values) (*FinancingQuery, error) {
+	q := &FinancingQuery{}
+
+	if raw := values.Get("down_payment"); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("%w: down_payment must be a non-negative number", ErrInvalidFinancing)
+		}
+		q.DownPayment = v
+	}
+
+	raw := values.Get("term_months")
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > models.MaxFinancingTerm {
+		return nil, fmt.Errorf("%w: term_months must be between 1 and %d", ErrInvalidFinancing, models.MaxFinancingTerm)
+	}
+	q.TermMonths = v
+
+	if raw := values.Get("rate"); raw != "" {
+		rate, err := strconv.ParseFloat(raw, 64)
+		if err != nil || rate < 0 || rate > 100 || math.IsNaN(rate) {
+			return nil, fmt.Errorf("%w: rate must be an annual percentage between 0 and 100", ErrInvalidFinancing)
+		}
+		q.Rate = &rate
+	}
+
+	if raw := values.Get("product_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			return nil, fmt.Errorf("%w: invalid product_id", ErrInvalidFinancing)
+		}
+		q.ProductID = uint(id)
+	}
+
+	if (q.Rate == nil) == (q.ProductID == 0) {
+		return nil, fmt.Errorf("%w: exactly one of rate and product_id is required", ErrInvalidFinancing)
+	}
+	return q, nil
+}
+
+// FinancingProductRef names the product a calculation was based on
+type FinancingProductRef struct {
+	ID   uint                 `json:"id"`
+	Name string               `json:"name"`
+	Type models.FinancingType `json:"type"`
+}
+
+// AmortizationRow is one monthly payment of a schedule
+type AmortizationRow struct {
+	Month     int     `json:"month"`
+	Payment   float64 `json:"payment"`
+	Principal float64 `json:"principal"`
+	Interest  float64 `json:"interest"`
+	Balance   float64 `json:"balance"`
+}
+
+// FinancingPlan is the result of a financing calculation.
+// TotalCost is the down payment plus every monthly payment; a lease's residual value is listed separately.
+type FinancingPlan struct {
+	CarID          uint                 `json:"car_id"`
+	Product        *FinancingProductRef `json:"product,omitempty"`
+	Price          float64              `json:"price"`
+	Currency       string               `json:"currency"`
+	DownPayment    float64              `json:"down_payment"`
+	FinancedAmount float64              `json:"financed_amount"`
+	ResidualValue  float64              `json:"residual_value"`
+	TermMonths     int                  `json:"term_months"`
+	AnnualRate     float64              `json:"annual_rate"`
+	MonthlyPayment float64              `json:"monthly_payment"`
+	TotalInterest  float64              `json:"total_interest"`
+	TotalCost      float64              `json:"total_cost"`
+	Schedule       []AmortizationRow    `json:"schedule"`
+}
+
+// FinancingService manages financing products and calculates loan and lease payments
+type FinancingService struct {
+	db *gorm.DB
+}
+
+// NewFinancingService creates a new instance of FinancingService
+func NewFinancingService(db *gorm.DB) *FinancingService {
+	return &FinancingService{db: db}
+}
+
+// Calculate builds the payment plan of a car for the query
+func (s *FinancingService) Calculate(carID uint, q *FinancingQuery) (*FinancingPlan, error) {
+	var car models.Car
+	if err := s.db.Select("id", "price", "currency").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	if car.Price <= 0 {
+		return nil, fmt.Errorf("%w: car has no price", ErrInvalidFinancing)
+	}
+	if q.DownPayment >= car.Price {
+		return nil, fmt.Errorf("%w: down_payment must be below the price", ErrInvalidFinancing)
+	}
+
+	// Суммы округляются по правилу валюты машины
+	currency, err := currencyRule(s.db, car.Currency)
+	if err != nil {
+		return nil, err
+	}
+	roundMoney := currency.Round
+
+	plan := &FinancingPlan{
+		CarID:       car.ID,
+		Price:       car.Price,
+		Currency:    car.Currency,
+		DownPayment: roundMoney(q.DownPayment),
+		TermMonths:  q.TermMonths,
+	}
+
+	if q.Rate != nil {
+		plan.AnnualRate = *q.Rate
+	} else {
+		product, err := s.GetProduct(q.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		rate, ok := product.RateFor(q.TermMonths)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s offers terms of %s months", ErrInvalidFinancing, product.Name, productTerms(product))
+		}
+		minDown := roundMoney(car.Price * product.MinDownPaymentPercent / 100)
+		if plan.DownPayment < minDown {
+			return nil, fmt.Errorf("%w: %s requires a down payment of at least %.*f", ErrInvalidFinancing, product.Name, currency.Decimals, minDown)
+		}
+		plan.AnnualRate = rate
+		plan.ResidualValue = roundMoney(car.Price * product.ResidualPercent / 100)
+		plan.Product = &FinancingProductRef{ID: product.ID, Name: product.Name, Type: product.Type}
+	}
+
+	plan.FinancedAmount = roundMoney(car.Price - plan.DownPayment)
+	if plan.ResidualValue >= plan.FinancedAmount {
+		return nil, fmt.Errorf("%w: the residual value leaves nothing to finance", ErrInvalidFinancing)
+	}
+
+	plan.Schedule = amortize(plan.FinancedAmount, plan.ResidualValue, plan.AnnualRate, plan.TermMonths, roundMoney)
+	plan.MonthlyPayment = plan.Schedule[0].Payment
+	total := plan.DownPayment
+	for _, row := range plan.Schedule {
+		plan.TotalInterest += row.Interest
+		total += row.Payment
+	}
+	plan.TotalInterest = roundMoney(plan.TotalInterest)
+	plan.TotalCost = roundMoney(total)
+	return plan, nil
+}
+
+// amortize builds an annuity schedule that pays principal down to residual over term months.
+// Amounts are rounded with roundMoney; the last payment absorbs the rounding difference.
+func amortize(principal, residual, annualRate float64, term int, roundMoney func(float64) float64) []AmortizationRow {
+	r := annualRate / 100 / 12
+	n := float64(term)
+
+	var payment float64
+	if r == 0 {
+		payment = (principal - residual) / n
+	} else {
+		growth := math.Pow(1+r, n)
+		payment = (principal - residual/growth) * r / (1 - 1/growth)
+	}
+	payment = roundMoney(payment)
+
+	schedule := make([]AmortizationRow, 0, term)
+	balance := principal
+	for month := 1; month <= term; month++ {
+		interest := roundMoney(balance * r)
+		paid := payment - interest
+		if month == term {
+			paid = balance - residual
+		}
+		balance = roundMoney(balance - paid)
+		schedule = append(schedule, AmortizationRow{
+			Month:     month,
+			Payment:   roundMoney(paid + interest),
+			Principal: roundMoney(paid),
+			Interest:  interest,
+			Balance:   balance,
+		})
+	}
+	return schedule
+}
+
+// ListProducts returns every financing product with its rates
+func (s *FinancingService) ListProducts() ([]models.FinancingProduct, error) {
+	products := []models.FinancingProduct{}
+	err := s.db.Preload("Rates", func(db *gorm.DB) *gorm.DB { return db.Order("term_months") }).
+		Order("LOWER(name), id").
+		Find(&products).Error
+	return products, err
+}
+
+// GetProduct returns a financing product with its rates
+func (s *FinancingService) GetProduct(id uint) (*models.FinancingProduct, error) {
+	var product models.FinancingProduct
+	err := s.db.Preload("Rates", func(db *gorm.DB) *gorm.DB { return db.Order("term_months") }).
+		First(&product, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFinancingProductNotFound
+		}
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateProduct adds a financing product with its rate table
+func (s *FinancingService) CreateProduct(product *models.FinancingProduct) (*models.FinancingProduct, error) {
+	product.ID = 0
+	for i := range product.Rates {
+		product.Rates[i].ID = 0
+	}
+	if err := product.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureUniqueProductName(tx, 0, product.Name); err != nil {
+			return err
+		}
+		return tx.Create(product).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProduct(product.ID)
+}
+
+// UpdateProduct replaces a financing product and its rate table
+func (s *FinancingService) UpdateProduct(id uint, product *models.FinancingProduct) (*models.FinancingProduct, error) {
+	if err := product.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.FinancingProduct
+		if err := tx.First(&current, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrFinancingProductNotFound
+			}
+			return err
+		}
+		if err := ensureUniqueProductName(tx, id, product.Name); err != nil {
+			return err
+		}
+
+		err := tx.Model(&current).Updates(map[string]interface{}{
+			"name":                     product.Name,
+			"type":                     product.Type,
+			"min_down_payment_percent": product.MinDownPaymentPercent,
+			"residual_percent":         product.ResidualPercent,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("product_id = ?", id).Delete(&models.FinancingRate{}).Error; err != nil {
+			return err
+		}
+		rates := make([]models.FinancingRate, len(product.Rates))
+		for i, rate := range product.Rates {
+			rates[i] = models.FinancingRate{ProductID: id, TermMonths: rate.TermMonths, AnnualRate: rate.AnnualRate}
+		}
+		return tx.Create(&rates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProduct(id)
+}
+
+// DeleteProduct removes a financing product and its rates
+func (s *FinancingService) DeleteProduct(id uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", id).Delete(&models.FinancingRate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.FinancingProduct{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrFinancingProductNotFound
+		}
+		return nil
+	})
+}
+
+func ensureUniqueProductName(tx *gorm.DB, id uint, name string) error {
+	var count int64
+	err := tx.Model(&models.FinancingProduct{}).
+		Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrFinancingProductExists
+	}
+	return nil
+}
+
+// productTerms lists the terms of a product, e.g. "12, 24, 36"
+func productTerms(product *models.FinancingProduct) string {
+	terms := make([]string, len(product.Rates))
+	for i, rate := range product.Rates {
+		terms[i] = strconv.Itoa(rate.TermMonths)
+	}
+	return strings.Join(terms, ", ")
+}
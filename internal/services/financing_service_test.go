@@ -0,0 +1,78 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"math"
+	"testing"
+)
+
+func TestAmortize(t *testing.T) {
+	cents := &models.ExchangeRate{Currency: "USD", Decimals: 2}
+	whole := &models.ExchangeRate{Currency: "JPY", Decimals: 0}
+
+	tests := []struct {
+		name      string
+		principal float64
+		residual  float64
+		rate      float64
+		term      int
+		currency  *models.ExchangeRate
+	}{
+		{name: "loan", principal: 15000, rate: 9.9, term: 36, currency: cents},
+		{name: "zero rate", principal: 10000, rate: 0, term: 7, currency: cents},
+		{name: "one month", principal: 10000, rate: 12, term: 1, currency: cents},
+		{name: "lease residual", principal: 20000, residual: 9000, rate: 6.5, term: 24, currency: cents},
+		{name: "zero rate lease", principal: 20000, residual: 9000, rate: 0, term: 9, currency: cents},
+		{name: "zero-decimal currency", principal: 2500000, rate: 4.5, term: 60, currency: whole},
+		{name: "zero-decimal lease", principal: 2500000, residual: 800000, rate: 3.9, term: 37, currency: whole},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule := amortize(tt.principal, tt.residual, tt.rate, tt.term, tt.currency.Round)
+			if len(schedule) != tt.term {
+				t.Fatalf("got %d rows, want %d", len(schedule), tt.term)
+			}
+
+			var paid, principal, interest float64
+			for i, row := range schedule {
+				if row.Month != i+1 {
+					t.Fatalf("row %d has month %d", i, row.Month)
+				}
+				for _, amount := range []float64{row.Payment, row.Principal, row.Interest, row.Balance} {
+					if amount != tt.currency.Round(amount) {
+						t.Fatalf("month %d: %v is not rounded to %d decimals", row.Month, amount, tt.currency.Decimals)
+					}
+				}
+				if !sameAmount(row.Payment, row.Principal+row.Interest) {
+					t.Fatalf("month %d: payment %v is not principal %v plus interest %v", row.Month, row.Payment, row.Principal, row.Interest)
+				}
+				if tt.rate == 0 && row.Interest != 0 {
+					t.Fatalf("month %d: interest %v at a zero rate", row.Month, row.Interest)
+				}
+				paid += row.Payment
+				principal += row.Principal
+				interest += row.Interest
+			}
+
+			if last := schedule[len(schedule)-1].Balance; !sameAmount(last, tt.residual) {
+				t.Fatalf("final balance %v, want the residual %v", last, tt.residual)
+			}
+			if !sameAmount(principal, tt.principal-tt.residual) {
+				t.Fatalf("principal paid %v, want %v", principal, tt.principal-tt.residual)
+			}
+			if !sameAmount(paid, tt.principal-tt.residual+interest) {
+				t.Fatalf("payments sum to %v, want principal %v plus interest %v", paid, tt.principal-tt.residual, interest)
+			}
+			// Последний платёж добирает только накопленную ошибку округления
+			unit := math.Pow10(-tt.currency.Decimals)
+			if first, last := schedule[0].Payment, schedule[len(schedule)-1].Payment; math.Abs(last-first) > float64(tt.term)*unit {
+				t.Fatalf("last payment %v is far from the monthly payment %v", last, first)
+			}
+		})
+	}
+}
+
+// sameAmount compares money sums that went through float additions
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
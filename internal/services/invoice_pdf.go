@@ -0,0 +1,93 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WriteInvoicePDF renders the invoice of an order with its car and invoice loaded as a one-page PDF
+func WriteInvoicePDF(w io.Writer, order *models.Order) error {
+	invoice := order.Invoice
+	if invoice == nil {
+		return ErrInvoiceNotIssued
+	}
+
+	const left, right = 50.0, 545.0
+	page := &pdfPage{}
+	y := 780.0
+
+	page.Text(left, y, 22, true, "INVOICE")
+	page.Text(360, y, 11, true, invoice.Number)
+	y -= 18
+	page.Text(360, y, 10, false, "Issued: "+invoice.IssuedAt.Format("2006-01-02"))
+	y -= 14
+	page.Text(360, y, 10, false, fmt.Sprintf("Order: #%d (%s)", order.ID, order.Status))
+
+	y -= 40
+	page.Text(left, y, 10, true, "Seller")
+	page.Text(300, y, 10, true, "Bill to")
+	y -= 15
+	page.Text(left, y, 10, false, invoice.SellerName)
+	page.Text(300, y, 10, false, invoice.BuyerName)
+	y -= 14
+	page.Text(300, y, 10, false, invoice.BuyerEmail)
+
+	y -= 40
+	page.Text(left, y, 10, true, "Description")
+	page.Text(300, y, 10, true, "VIN")
+	page.Text(460, y, 10, true, "Amount, "+valueOr(invoice.Currency, models.BaseCurrency))
+	y -= 6
+	page.Line(left, y, right, y)
+	y -= 16
+	page.Text(left, y, 10, false, invoice.CarDescription)
+	page.Text(300, y, 10, false, valueOr(invoice.VIN, "-"))
+	page.Text(460, y, 10, false, formatAmount(invoice.Amount))
+	y -= 10
+	page.Line(left, y, right, y)
+	y -= 18
+	page.Text(380, y, 11, true, "Total")
+	page.Text(460, y, 11, true, formatAmount(invoice.Amount))
+
+	if order.Status == models.OrderPaid || order.Status == models.OrderDelivered {
+		y -= 40
+		page.Text(left, y, 12, true, "PAID")
+	} else if order.Status == models.OrderCancelled {
+		y -= 40
+		page.Text(left, y, 12, true, "CANCELLED")
+	}
+
+	page.Text(left, 50, 8, false, "Generated "+time.Now().Format("2006-01-02 15:04"))
+
+	_, err := page.WriteTo(w)
+	return err
+}
+
+// formatAmount formats a money amount with two decimals and spaces between thousands
+func formatAmount(amount float64) string {
+	raw := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(raw, "-") {
+		sign, raw = "-", raw[1:]
+	}
+	whole, frac := raw[:len(raw)-3], raw[len(raw)-3:]
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteRune(digit)
+	}
+	return sign + b.String() + frac
+}
+
+func valueOr(s, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Messaging errors
+var (
+	ErrThreadNotFound  = errors.New("thread not found")
+	ErrThreadForbidden = errors.New("not a participant of this thread")
+	ErrThreadWithSelf  = errors.New("cannot start a conversation about your own car")
+	ErrSenderBlocked   = errors.New("blocked users cannot send messages")
+)
+
+// ThreadSummary is a thread as listed in an inbox, with the caller's unread count and the latest message
+type ThreadSummary struct {
+	models.MessageThread
+	UnreadCount int64           `json:"unread_count"`
+	LastMessage *models.Message `json:"last_message"`
+}
+
+// ThreadDetail is a thread with a page of its messages, newest first
+type ThreadDetail struct {
+	models.MessageThread
+	Messages []models.Message `json:"messages"`
+	Total    int64            `json:"total"`
+}
+
+// MessageService manages buyer–seller conversations about cars
+type MessageService struct {
+	db *gorm.DB
+}
+
+func NewMessageService(db *gorm.DB) *MessageService {
+	return &MessageService{db: db}
+}
+
+// StartThread opens the conversation of a buyer about a car, or reuses the existing one, and posts the first message.
+// The seller side is the owner of the car, or the admins for dealership cars.
+func (s *MessageService) StartThread(buyerID uint, admin bool, carID uint, body string) (*models.MessageThread, *models.Message, error) {
+	message := models.Message{SenderID: buyerID, Body: body}
+	if err := message.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	var thread models.MessageThread
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSenderActive(tx, buyerID); err != nil {
+			return err
+		}
+
+		var car models.Car
+		if err := tx.Select("id", "owner_id").First(&car, carID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if (car.OwnerID != nil && *car.OwnerID == buyerID) || (car.OwnerID == nil && admin) {
+			return ErrThreadWithSelf
+		}
+
+		// Повторный запрос по той же машине продолжает существующую переписку
+		var count int64
+		if err := tx.Model(&models.MessageThread{}).Where("car_id = ? AND buyer_id = ?", carID, buyerID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			created := models.MessageThread{CarID: carID, BuyerID: buyerID, SellerID: car.OwnerID, LastMessageAt: time.Now()}
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "car_id"}, {Name: "buyer_id"}},
+				DoNothing: true,
+			}).Create(&created).Error
+			if err != nil {
+				return err
+			}
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("car_id = ? AND buyer_id = ?", carID, buyerID).
+			First(&thread).Error; err != nil {
+			return err
+		}
+
+		return postMessageTx(tx, &thread, true, &message)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.db.Preload("Car").First(&thread, thread.ID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &thread, &message, nil
+}
+
+// PostMessage adds a message from a participant to a thread
+func (s *MessageService) PostMessage(threadID, senderID uint, admin bool, body string) (*models.Message, error) {
+	message := models.Message{SenderID: senderID, Body: body}
+	if err := message.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureSenderActive(tx, senderID); err != nil {
+			return err
+		}
+
+		var thread models.MessageThread
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&thread, threadID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrThreadNotFound
+			}
+			return err
+		}
+		buyer, ok := threadSide(&thread, senderID, admin)
+		if !ok {
+			return ErrThreadForbidden
+		}
+
+		return postMessageTx(tx, &thread, buyer, &message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// ListThreads returns a page of the user's threads, most recently active first, with unread counts.
+// Admins also see every thread about a dealership car.
+func (s *MessageService) ListThreads(userID uint, admin bool, page, perPage int) ([]ThreadSummary, int64, error) {
+	query := s.db.Model(&models.MessageThread{})
+	if admin {
+		query = query.Where("buyer_id = ? OR seller_id = ? OR seller_id IS NULL", userID, userID)
+	} else {
+		query = query.Where("buyer_id = ? OR seller_id = ?", userID, userID)
+	}
+	return s.listThreads(query, userID, page, perPage)
+}
+
+// ListAllThreads returns a page of every thread for moderation, optionally filtered by car and participant.
+// Unread counts are not computed for moderators.
+func (s *MessageService) ListAllThreads(carID, userID uint, page, perPage int) ([]ThreadSummary, int64, error) {
+	query := s.db.Model(&models.MessageThread{})
+	if carID != 0 {
+		query = query.Where("car_id = ?", carID)
+	}
+	if userID != 0 {
+		query = query.Where("buyer_id = ? OR seller_id = ?", userID, userID)
+	}
+	return s.listThreads(query, 0, page, perPage)
+}
+
+// GetThread returns a thread with a page of its messages to one of its participants
+func (s *MessageService) GetThread(threadID, userID uint, admin bool, page, perPage int) (*ThreadDetail, error) {
+	thread, err := s.findThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := threadSide(thread, userID, admin); !ok {
+		return nil, ErrThreadForbidden
+	}
+	return s.threadDetail(thread, page, perPage)
+}
+
+// ModerateThread returns any thread with a page of its messages without marking anything as read
+func (s *MessageService) ModerateThread(threadID uint, page, perPage int) (*ThreadDetail, error) {
+	thread, err := s.findThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+	return s.threadDetail(thread, page, perPage)
+}
+
+// MarkThreadRead marks every message of a thread as read for the caller's side
+func (s *MessageService) MarkThreadRead(threadID, userID uint, admin bool) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var thread models.MessageThread
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&thread, threadID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrThreadNotFound
+			}
+			return err
+		}
+		buyer, ok := threadSide(&thread, userID, admin)
+		if !ok {
+			return ErrThreadForbidden
+		}
+
+		var lastID uint
+		if err := tx.Model(&models.Message{}).Where("thread_id = ?", thread.ID).
+			Select("COALESCE(MAX(id), 0)").Scan(&lastID).Error; err != nil {
+			return err
+		}
+		return tx.Model(&thread).Update(lastReadColumn(buyer), lastID).Error
+	})
+}
+
+// UnreadCount returns the number of unread messages across all threads of the user
+func (s *MessageService) UnreadCount(userID uint, admin bool) (int64, error) {
+	scope := "t.buyer_id = ? OR t.seller_id = ?"
+	if admin {
+		scope += " OR t.seller_id IS NULL"
+	}
+
+	var count int64
+	err := s.db.Table("messages AS m").
+		Joins("JOIN message_threads t ON t.id = m.thread_id").
+		Where("("+scope+") AND m.sender_id <> ?", userID, userID, userID).
+		Where("m.id > CASE WHEN t.buyer_id = ? THEN t.buyer_last_read_id ELSE t.seller_last_read_id END", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (s *MessageService) findThread(id uint) (*models.MessageThread, error) {
+	var thread models.MessageThread
+	if err := s.db.Preload("Car").First(&thread, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrThreadNotFound
+		}
+		return nil, err
+	}
+	return &thread, nil
+}
+
+func (s *MessageService) threadDetail(thread *models.MessageThread, page, perPage int) (*ThreadDetail, error) {
+	detail := &ThreadDetail{MessageThread: *thread, Messages: []models.Message{}}
+	query := s.db.Model(&models.Message{}).Where("thread_id = ?", thread.ID)
+	if err := query.Count(&detail.Total).Error; err != nil {
+		return nil, err
+	}
+	err := query.Order("id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&detail.Messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// listThreads pages through the threads of query and adds the latest message and,
+// when userID is set, the number of messages unread by that user
+func (s *MessageService) listThreads(query *gorm.DB, userID uint, page, perPage int) ([]ThreadSummary, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	summaries := []ThreadSummary{}
+	if total == 0 {
+		return summaries, 0, nil
+	}
+
+	var threads []models.MessageThread
+	err := query.Preload("Car").
+		Order("last_message_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&threads).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]uint, len(threads))
+	for i, thread := range threads {
+		ids[i] = thread.ID
+	}
+
+	var latest []models.Message
+	err = s.db.Where("id IN (?)", s.db.Model(&models.Message{}).Select("MAX(id)").Where("thread_id IN ?", ids).Group("thread_id")).
+		Find(&latest).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	lastMessages := make(map[uint]models.Message, len(latest))
+	for _, message := range latest {
+		lastMessages[message.ThreadID] = message
+	}
+
+	unread := map[uint]int64{}
+	if userID != 0 {
+		var counts []struct {
+			ThreadID uint
+			Count    int64
+		}
+		err := s.db.Table("messages AS m").
+			Select("m.thread_id, COUNT(*) AS count").
+			Joins("JOIN message_threads t ON t.id = m.thread_id").
+			Where("m.thread_id IN ? AND m.sender_id <> ?", ids, userID).
+			Where("m.id > CASE WHEN t.buyer_id = ? THEN t.buyer_last_read_id ELSE t.seller_last_read_id END", userID).
+			Group("m.thread_id").
+			Scan(&counts).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, c := range counts {
+			unread[c.ThreadID] = c.Count
+		}
+	}
+
+	for _, thread := range threads {
+		summary := ThreadSummary{MessageThread: thread, UnreadCount: unread[thread.ID]}
+		if message, ok := lastMessages[thread.ID]; ok {
+			summary.LastMessage = &message
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries, total, nil
+}
+
+// postMessageTx stores a message in a locked thread; the sender has read everything up to it
+func postMessageTx(tx *gorm.DB, thread *models.MessageThread, fromBuyer bool, message *models.Message) error {
+	message.ThreadID = thread.ID
+	if err := tx.Create(message).Error; err != nil {
+		return err
+	}
+	thread.LastMessageAt = message.CreatedAt
+	return tx.Model(thread).Updates(map[string]interface{}{
+		"last_message_at":         message.CreatedAt,
+		lastReadColumn(fromBuyer): message.ID,
+	}).Error
+}
+
+// threadSide reports whether the user takes part in the thread and whether as the buyer
+func threadSide(thread *models.MessageThread, userID uint, admin bool) (buyer bool, ok bool) {
+	if thread.BuyerID == userID {
+		return true, true
+	}
+	if thread.SellerID != nil {
+		return false, *thread.SellerID == userID
+	}
+	return false, admin
+}
+
+func lastReadColumn(buyer bool) string {
+	if buyer {
+		return "buyer_last_read_id"
+	}
+	return "seller_last_read_id"
+}
+
+// ensureSenderActive rejects messages from blocked users
+func ensureSenderActive(tx *gorm.DB, userID uint) error {
+	var user models.User
+	if err := tx.Select("id", "is_blocked").First(&user, userID).Error; err != nil {
+		return err
+	}
+	if user.IsBlocked {
+		return ErrSenderBlocked
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNotificationNotFound is returned when a notification does not exist or belongs to another user
+var ErrNotificationNotFound = errors.New("notification not found")
+
+type NotificationService struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) *NotificationService {
+	return &NotificationService{db: db}
+}
+
+// GetUserNotifications returns a page of the user's inbox, newest first
+func (s *NotificationService) GetUserNotifications(userID uint, unreadOnly bool, page, perPage int) ([]models.Notification, int64, error) {
+	query := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	notifications := []models.Notification{}
+	if total == 0 {
+		return notifications, 0, nil
+	}
+
+	err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// UnreadCount returns the number of unread notifications of the user
+func (s *NotificationService) UnreadCount(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkAsRead marks a single notification of the user as read
+func (s *NotificationService) MarkAsRead(userID, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+
+	if notification.IsRead() {
+		return &notification, nil
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+		return nil, err
+	}
+	notification.ReadAt = &now
+
+	return &notification, nil
+}
+
+// MarkAllAsRead marks every unread notification of the user as read and returns how many were changed
+func (s *NotificationService) MarkAllAsRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,475 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultOfferTTL is how long an offer waits for an answer unless configured otherwise
+const DefaultOfferTTL = 72 * time.Hour
+
+// Offer errors
+var (
+	ErrOfferNotFound    = errors.New("offer not found")
+	ErrOfferForbidden   = errors.New("not authorized to act on this offer")
+	ErrOfferNotYourTurn = errors.New("offer is waiting for the other party")
+	ErrOfferOwnCar      = errors.New("cannot make an offer on your own car")
+	ErrOfferExists      = errors.New("an open offer on this car already exists")
+	ErrOfferExpired     = errors.New("offer has expired")
+	ErrInvalidOffer     = errors.New("invalid offer")
+)
+
+// OfferService handles price offers and their negotiation
+type OfferService struct {
+	db           *gorm.DB
+	reservations *ReservationService
+	ttl          time.Duration
+}
+
+// NewOfferService creates a new instance of OfferService. Every move gives the other party ttl to answer;
+// accepted offers reserve the car through reservations.
+func NewOfferService(db *gorm.DB, reservations *ReservationService, ttl time.Duration) *OfferService {
+	if ttl <= 0 {
+		ttl = DefaultOfferTTL
+	}
+	return &OfferService{db: db, reservations: reservations, ttl: ttl}
+}
+
+// SubmitOffer places a buyer's offer on an available car. A buyer may have one open offer per car.
+func (s *OfferService) SubmitOffer(buyerID uint, admin bool, carID uint, amount float64, message string) (*models.Offer, error) {
+	message, err := offerInput(&amount, message)
+	if err != nil {
+		return nil, err
+	}
+
+	var offer models.Offer
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var car models.Car
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status", "owner_id", "currency").First(&car, carID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if (car.OwnerID != nil && *car.OwnerID == buyerID) || (car.OwnerID == nil && admin) {
+			return ErrOfferOwnCar
+		}
+		if car.Status != models.StatusAvailable {
+			return ErrCarNotAvailable
+		}
+
+		var open int64
+		err := tx.Model(&models.Offer{}).
+			Where("car_id = ? AND buyer_id = ? AND status IN ?", carID, buyerID, []models.OfferStatus{models.OfferPending, models.OfferCountered}).
+			Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrOfferExists
+		}
+		if amount, err = roundOfferAmount(tx, amount, car.Currency); err != nil {
+			return err
+		}
+
+		offer = models.Offer{
+			CarID:     carID,
+			BuyerID:   buyerID,
+			SellerID:  car.OwnerID,
+			Amount:    amount,
+			Currency:  car.Currency,
+			Status:    models.OfferPending,
+			ExpiresAt: time.Now().Add(s.ttl),
+		}
+		if err := tx.Create(&offer).Error; err != nil {
+			return err
+		}
+		return addOfferEventTx(tx, offer.ID, &buyerID, models.OfferActionSubmitted, &amount, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOffer(offer.ID, buyerID, admin)
+}
+
+// CounterOffer answers an open offer with a new amount in the car's currency. A counter from the seller side
+// waits for the buyer, a counter from the buyer waits for the seller side again.
+func (s *OfferService) CounterOffer(id, actorID uint, admin bool, amount float64, message string) (*models.Offer, error) {
+	message, err := offerInput(&amount, message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		offer, buyer, err := lockOfferTurn(tx, id, actorID, admin)
+		if err != nil {
+			return err
+		}
+		next := models.OfferCountered
+		if buyer {
+			next = models.OfferPending
+		}
+		if err := offer.Status.CanTransitionTo(next); err != nil {
+			return err
+		}
+
+		// Встречная сумма названа в текущей валюте машины
+		var car models.Car
+		if err := tx.Select("id", "currency").First(&car, offer.CarID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+		if amount, err = roundOfferAmount(tx, amount, car.Currency); err != nil {
+			return err
+		}
+
+		err = tx.Model(offer).Updates(map[string]interface{}{
+			"status":     next,
+			"amount":     amount,
+			"currency":   car.Currency,
+			"expires_at": time.Now().Add(s.ttl),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return addOfferEventTx(tx, offer.ID, &actorID, models.OfferActionCountered, &amount, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOffer(id, actorID, admin)
+}
+
+// AcceptOffer accepts the amount on the table and reserves the car for the buyer.
+// Other open offers on the car are rejected.
+func (s *OfferService) AcceptOffer(id, actorID uint, admin bool, message string) (*models.Offer, error) {
+	message, err := offerMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var current models.Offer
+		if err := tx.Select("id", "car_id").First(&current, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOfferNotFound
+			}
+			return err
+		}
+		// Машину блокируем раньше предложения, в том же порядке, что и при подаче и удалении машины
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&models.Car{}, current.CarID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+
+		offer, _, err := lockOfferTurn(tx, id, actorID, admin)
+		if err != nil {
+			return err
+		}
+		if err := offer.Status.CanTransitionTo(models.OfferAccepted); err != nil {
+			return err
+		}
+
+		reservation, err := s.reservations.reserveCarTx(tx, offer.BuyerID, offer.CarID, fmt.Sprintf("offer %d accepted", offer.ID))
+		if err != nil {
+			return err
+		}
+		err = tx.Model(offer).Updates(map[string]interface{}{
+			"status":         models.OfferAccepted,
+			"reservation_id": reservation.ID,
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := addOfferEventTx(tx, offer.ID, &actorID, models.OfferActionAccepted, &offer.Amount, message); err != nil {
+			return err
+		}
+
+		return closeCarOffersTx(tx, offer.CarID, offer.ID, models.OfferRejected, models.OfferActionRejected, "car reserved for another offer")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOffer(id, actorID, admin)
+}
+
+// RejectOffer declines the amount on the table and closes the offer
+func (s *OfferService) RejectOffer(id, actorID uint, admin bool, message string) (*models.Offer, error) {
+	message, err := offerMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		offer, _, err := lockOfferTurn(tx, id, actorID, admin)
+		if err != nil {
+			return err
+		}
+		if err := offer.Status.CanTransitionTo(models.OfferRejected); err != nil {
+			return err
+		}
+		if err := tx.Model(offer).Update("status", models.OfferRejected).Error; err != nil {
+			return err
+		}
+		return addOfferEventTx(tx, offer.ID, &actorID, models.OfferActionRejected, nil, message)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOffer(id, actorID, admin)
+}
+
+// WithdrawOffer lets the buyer take back an open offer at any point of the negotiation
+func (s *OfferService) WithdrawOffer(id, buyerID uint) (*models.Offer, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		offer, err := lockOffer(tx, id)
+		if err != nil {
+			return err
+		}
+		if offer.BuyerID != buyerID {
+			return ErrOfferForbidden
+		}
+		if err := offer.Status.CanTransitionTo(models.OfferWithdrawn); err != nil {
+			return err
+		}
+		if err := tx.Model(offer).Update("status", models.OfferWithdrawn).Error; err != nil {
+			return err
+		}
+		return addOfferEventTx(tx, offer.ID, &buyerID, models.OfferActionWithdrawn, nil, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOffer(id, buyerID, false)
+}
+
+// GetOffer returns an offer with its full negotiation history to one of its parties or an admin
+func (s *OfferService) GetOffer(id, userID uint, admin bool) (*models.Offer, error) {
+	var offer models.Offer
+	err := s.db.Preload("Car").
+		Preload("Events", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		First(&offer, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOfferNotFound
+		}
+		return nil, err
+	}
+	if _, ok := offerSide(&offer, userID, admin); !ok && !admin {
+		return nil, ErrOfferForbidden
+	}
+	return &offer, nil
+}
+
+// GetUserOffers returns the offers the user made as a buyer or received as the seller side, newest first.
+// Admins receive the offers on dealership cars.
+func (s *OfferService) GetUserOffers(userID uint, admin bool, status models.OfferStatus) ([]models.Offer, error) {
+	query := s.db.Preload("Car")
+	if admin {
+		query = query.Where("buyer_id = ? OR seller_id = ? OR seller_id IS NULL", userID, userID)
+	} else {
+		query = query.Where("buyer_id = ? OR seller_id = ?", userID, userID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	offers := []models.Offer{}
+	if err := query.Order("created_at DESC, id DESC").Find(&offers).Error; err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// ListOffers returns all offers for admins, optionally filtered by status and car
+func (s *OfferService) ListOffers(status models.OfferStatus, carID uint) ([]models.Offer, error) {
+	query := s.db.Preload("Car")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if carID != 0 {
+		query = query.Where("car_id = ?", carID)
+	}
+
+	offers := []models.Offer{}
+	if err := query.Order("created_at DESC, id DESC").Find(&offers).Error; err != nil {
+		return nil, err
+	}
+	return offers, nil
+}
+
+// ExpireStaleOffers closes open offers that were not answered in time and returns how many were expired
+func (s *OfferService) ExpireStaleOffers(now time.Time) (int, error) {
+	var stale []models.Offer
+	err := s.db.Where("status IN ? AND expires_at <= ?", []models.OfferStatus{models.OfferPending, models.OfferCountered}, now).
+		Find(&stale).Error
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, offer := range stale {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			// Предложение могли принять или продлить параллельно
+			result := tx.Model(&models.Offer{}).
+				Where("id = ? AND status IN ? AND expires_at <= ?", offer.ID, []models.OfferStatus{models.OfferPending, models.OfferCountered}, now).
+				Update("status", models.OfferExpired)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			expired++
+			return addOfferEventTx(tx, offer.ID, nil, models.OfferActionExpired, nil, "")
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// StartExpiryWorker periodically expires stale offers until the context is cancelled
+func (s *OfferService) StartExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.ExpireStaleOffers(time.Now())
+			if err != nil {
+				log.Printf("offer expiry failed: %v", err)
+				continue
+			}
+			if count > 0 {
+				log.Printf("expired %d offer(s)", count)
+			}
+		}
+	}
+}
+
+// lockOffer locks an offer row
+func lockOffer(tx *gorm.DB, id uint) (*models.Offer, error) {
+	var offer models.Offer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&offer, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOfferNotFound
+		}
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// lockOfferTurn locks an open offer and checks that it is the actor's turn to answer before it expires.
+// It reports whether the actor is the buyer.
+func lockOfferTurn(tx *gorm.DB, id, actorID uint, admin bool) (*models.Offer, bool, error) {
+	offer, err := lockOffer(tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	buyer, ok := offerSide(offer, actorID, admin)
+	if !ok {
+		return nil, false, ErrOfferForbidden
+	}
+	if !offer.Status.IsOpen() {
+		return nil, false, fmt.Errorf("%w: offer is %s", models.ErrIllegalOfferTransition, offer.Status)
+	}
+	// pending ждёт ответа продавца, countered — ответа покупателя
+	if buyer != (offer.Status == models.OfferCountered) {
+		return nil, false, ErrOfferNotYourTurn
+	}
+	// Воркер истечения мог ещё не дойти до предложения
+	if !offer.ExpiresAt.After(time.Now()) {
+		return nil, false, ErrOfferExpired
+	}
+	return offer, buyer, nil
+}
+
+// offerSide reports whether the user is a party of the offer and whether as the buyer
+func offerSide(offer *models.Offer, userID uint, admin bool) (buyer bool, ok bool) {
+	if offer.BuyerID == userID {
+		return true, true
+	}
+	if offer.SellerID != nil {
+		return false, *offer.SellerID == userID
+	}
+	return false, admin
+}
+
+// closeCarOffersTx closes every open offer on a car except exceptID with a system event
+func closeCarOffersTx(tx *gorm.DB, carID, exceptID uint, status models.OfferStatus, action models.OfferAction, reason string) error {
+	var ids []uint
+	err := tx.Model(&models.Offer{}).
+		Where("car_id = ? AND id <> ? AND status IN ?", carID, exceptID, []models.OfferStatus{models.OfferPending, models.OfferCountered}).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	if err := tx.Model(&models.Offer{}).Where("id IN ?", ids).Update("status", status).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := addOfferEventTx(tx, id, nil, action, nil, reason); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addOfferEventTx(tx *gorm.DB, offerID uint, actorID *uint, action models.OfferAction, amount *float64, message string) error {
+	return tx.Create(&models.OfferEvent{
+		OfferID: offerID,
+		ActorID: actorID,
+		Action:  action,
+		Amount:  amount,
+		Message: message,
+	}).Error
+}
+
+// offerInput validates an offered amount and trims the accompanying message
+func offerInput(amount *float64, message string) (string, error) {
+	if *amount <= 0 {
+		return "", fmt.Errorf("%w: amount must be positive", ErrInvalidOffer)
+	}
+	return offerMessage(message)
+}
+
+// roundOfferAmount rounds an offered amount by the rule of the car's currency
+func roundOfferAmount(tx *gorm.DB, amount float64, currency string) (float64, error) {
+	rule, err := currencyRule(tx, currency)
+	if err != nil {
+		return 0, err
+	}
+	amount = rule.Round(amount)
+	if amount <= 0 {
+		return 0, fmt.Errorf("%w: amount must be positive", ErrInvalidOffer)
+	}
+	return amount, nil
+}
+
+// offerMessage trims the message that goes with a step of the negotiation and checks its length
+func offerMessage(message string) (string, error) {
+	message = strings.TrimSpace(message)
+	if len([]rune(message)) > 500 {
+		return "", fmt.Errorf("%w: message must be at most 500 characters", ErrInvalidOffer)
+	}
+	return message, nil
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DealershipName is printed as the seller on invoices for dealership cars
+const DealershipName = "Cars Dealership"
+
+// Order errors
+var (
+	ErrOrderNotFound    = errors.New("order not found")
+	ErrOrderForbidden   = errors.New("not authorized to act on this order")
+	ErrOrderOwnCar      = errors.New("cannot order your own car")
+	ErrOrderExists      = errors.New("an order for this offer already exists")
+	ErrOfferNotAccepted = errors.New("only an accepted offer can be ordered")
+	ErrOfferHoldLapsed  = errors.New("the reservation of the accepted offer is no longer active")
+	ErrInvalidOrder     = errors.New("invalid order")
+	ErrCarHasOpenOrder  = errors.New("car has an open order")
+	ErrInvoiceNotIssued = errors.New("order has no invoice")
+	ErrOrderPaid        = errors.New("a paid order is cancelled by refunding its payment")
+)
+
+// OrderService handles purchase orders and their invoices
+type OrderService struct {
+	db *gorm.DB
+}
+
+// NewOrderService creates a new instance of OrderService
+func NewOrderService(db *gorm.DB) *OrderService {
+	return &OrderService{db: db}
+}
+
+// CreateOrder orders an available car at its list price. A car the buyer has reserved may be ordered too.
+func (s *OrderService) CreateOrder(buyerID uint, admin bool, carID uint) (*models.Order, error) {
+	var order *models.Order
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		car, err := lockOrderCar(tx, carID)
+		if err != nil {
+			return err
+		}
+		if (car.OwnerID != nil && *car.OwnerID == buyerID) || (car.OwnerID == nil && admin) {
+			return ErrOrderOwnCar
+		}
+		if car.Price <= 0 {
+			return fmt.Errorf("%w: car has no list price", ErrInvalidOrder)
+		}
+
+		order, err = placeOrderTx(tx, car, buyerID, car.Price, car.Currency, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(order.ID, buyerID, admin)
+}
+
+// CreateOrderFromOffer orders a car at the amount and in the currency of the buyer's accepted offer.
+// The reservation made when the offer was accepted is taken over by the order; once it has
+// expired or been cancelled the offer can no longer be ordered.
+func (s *OrderService) CreateOrderFromOffer(buyerID uint, offerID uint) (*models.Order, error) {
+	var order *models.Order
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var offer models.Offer
+		if err := tx.First(&offer, offerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOfferNotFound
+			}
+			return err
+		}
+		if offer.BuyerID != buyerID {
+			return ErrOfferForbidden
+		}
+		if offer.Status != models.OfferAccepted {
+			return ErrOfferNotAccepted
+		}
+
+		car, err := lockOrderCar(tx, offer.CarID)
+		if err != nil {
+			return err
+		}
+		// Проверяем после блокировки машины, чтобы два запроса не создали два заказа по одному предложению
+		var existing int64
+		err = tx.Model(&models.Order{}).
+			Where("offer_id = ? AND status <> ?", offer.ID, models.OrderCancelled).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrOrderExists
+		}
+		// Цена предложения действует, только пока держится бронь, созданная при его принятии
+		if offer.ReservationID == nil {
+			return ErrOfferHoldLapsed
+		}
+		var held int64
+		err = tx.Model(&models.Reservation{}).
+			Where("id = ? AND car_id = ? AND user_id = ? AND status = ?", *offer.ReservationID, offer.CarID, buyerID, models.ReservationActive).
+			Count(&held).Error
+		if err != nil {
+			return err
+		}
+		if held == 0 {
+			return ErrOfferHoldLapsed
+		}
+
+		order, err = placeOrderTx(tx, car, buyerID, offer.Amount, offer.Currency, &offer.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(order.ID, buyerID, false)
+}
+
+// UpdateOrderStatus moves an order to the next status. The buyer may only cancel;
+// the seller side and admins confirm, mark paid (e.g. for cash payments) and delivered.
+func (s *OrderService) UpdateOrderStatus(id, actorID uint, admin bool, to models.OrderStatus, reason string) (*models.Order, error) {
+	reason = strings.TrimSpace(reason)
+	if len([]rune(reason)) > 255 {
+		return nil, fmt.Errorf("%w: reason must be at most 255 characters", ErrInvalidOrder)
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := lockOrderTx(tx, id)
+		if err != nil {
+			return err
+		}
+		buyer, seller := orderSide(order, actorID, admin)
+		if !buyer && !seller && !admin {
+			return ErrOrderForbidden
+		}
+		if !seller && !admin && to != models.OrderCancelled {
+			return ErrOrderForbidden
+		}
+		if order.Status == models.OrderPaid && to == models.OrderCancelled {
+			return ErrOrderPaid
+		}
+		return applyOrderStatusTx(tx, order, to, &actorID, reason)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetOrder(id, actorID, admin)
+}
+
+// GetOrder returns an order with its car and invoice to the buyer, the seller or an admin
+func (s *OrderService) GetOrder(id, userID uint, admin bool) (*models.Order, error) {
+	var order models.Order
+	err := s.db.Preload("Car", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Invoice").
+		First(&order, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if buyer, seller := orderSide(&order, userID, admin); !buyer && !seller && !admin {
+		return nil, ErrOrderForbidden
+	}
+	return &order, nil
+}
+
+// ListUserOrders returns a page of the user's order history as a buyer and as a private seller, newest first
+func (s *OrderService) ListUserOrders(userID uint, status models.OrderStatus, page, perPage int) ([]models.Order, int64, error) {
+	query := s.db.Model(&models.Order{}).Where("buyer_id = ? OR seller_id = ?", userID, userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	return s.pageOrders(query, page, perPage)
+}
+
+// ListOrders returns a page of all orders for admins, optionally filtered by status, car and buyer
+func (s *OrderService) ListOrders(status models.OrderStatus, carID, buyerID uint, page, perPage int) ([]models.Order, int64, error) {
+	query := s.db.Model(&models.Order{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if carID != 0 {
+		query = query.Where("car_id = ?", carID)
+	}
+	if buyerID != 0 {
+		query = query.Where("buyer_id = ?", buyerID)
+	}
+	return s.pageOrders(query, page, perPage)
+}
+
+func (s *OrderService) pageOrders(query *gorm.DB, page, perPage int) ([]models.Order, int64, error) {
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orders := []models.Order{}
+	if total == 0 {
+		return orders, 0, nil
+	}
+
+	err := query.Preload("Car", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Invoice").
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&orders).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+// lockOrderTx locks an order and its car for a status change
+func lockOrderTx(tx *gorm.DB, id uint) (*models.Order, error) {
+	var current models.Order
+	if err := tx.Select("id", "car_id").First(&current, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	// Машину блокируем раньше заказа, как при создании заказа и удалении машины
+	if _, err := lockOrderCar(tx, current.CarID); err != nil {
+		return nil, err
+	}
+
+	var order models.Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// applyOrderStatusTx moves a locked order to the next status. Delivery marks the car sold,
+// cancellation puts it back on sale. actorID is nil for changes made by the system.
+func applyOrderStatusTx(tx *gorm.DB, order *models.Order, to models.OrderStatus, actorID *uint, reason string) error {
+	if err := order.Status.CanTransitionTo(to); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": to}
+	switch to {
+	case models.OrderConfirmed:
+		updates["confirmed_at"] = now
+	case models.OrderPaid:
+		updates["paid_at"] = now
+	case models.OrderDelivered:
+		updates["delivered_at"] = now
+		if _, err := changeCarStatusTx(tx, order.CarID, models.StatusSold, actorID, fmt.Sprintf("order %d delivered", order.ID), false); err != nil {
+			return err
+		}
+		if err := cancelCarTestDrivesTx(tx, order.CarID, actorID); err != nil {
+			return err
+		}
+	case models.OrderCancelled:
+		updates["cancelled_at"] = now
+		updates["cancelled_by"] = actorID
+		updates["cancel_reason"] = reason
+		if err := releaseReservedCarTx(tx, order.CarID, actorID, fmt.Sprintf("order %d cancelled", order.ID)); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(order).Updates(updates).Error; err != nil {
+		return err
+	}
+	order.Status = to
+	return nil
+}
+
+// lockOrderCar locks a car that is being ordered or whose order changes
+func lockOrderCar(tx *gorm.DB, carID uint) (*models.Car, error) {
+	var car models.Car
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	return &car, nil
+}
+
+// placeOrderTx takes the locked car off the market for the buyer, creates the order at price in currency and issues its invoice.
+// An available car is moved to reservation; a car the buyer already holds keeps its status and
+// the active reservation is completed, so the reservation expiry no longer releases it.
+func placeOrderTx(tx *gorm.DB, car *models.Car, buyerID uint, price float64, currency string, offerID *uint) (*models.Order, error) {
+	switch car.Status {
+	case models.StatusAvailable, "":
+		if _, err := changeCarStatusTx(tx, car.ID, models.StatusReservation, &buyerID, fmt.Sprintf("ordered by user %d", buyerID), false); err != nil {
+			return nil, err
+		}
+	case models.StatusReservation:
+		result := tx.Model(&models.Reservation{}).
+			Where("car_id = ? AND user_id = ? AND status = ?", car.ID, buyerID, models.ReservationActive).
+			Update("status", models.ReservationCompleted)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, ErrCarNotAvailable
+		}
+	default:
+		return nil, ErrCarNotAvailable
+	}
+
+	order := models.Order{
+		CarID:    car.ID,
+		BuyerID:  buyerID,
+		SellerID: car.OwnerID,
+		OfferID:  offerID,
+		Price:    price,
+		Currency: currency,
+		Status:   models.OrderPending,
+	}
+	if err := tx.Create(&order).Error; err != nil {
+		return nil, err
+	}
+	if err := issueInvoiceTx(tx, &order, car); err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// issueInvoiceTx numbers and stores the invoice of a new order.
+// Numbers run without gaps within a year because the year's counter row is locked until the order commits.
+func issueInvoiceTx(tx *gorm.DB, order *models.Order, car *models.Car) error {
+	now := time.Now()
+	year := now.Year()
+
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.InvoiceSequence{Year: year}).Error; err != nil {
+		return err
+	}
+	var seq models.InvoiceSequence
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&seq, "year = ?", year).Error; err != nil {
+		return err
+	}
+	seq.Last++
+	if err := tx.Model(&models.InvoiceSequence{}).Where("year = ?", year).Update("last", seq.Last).Error; err != nil {
+		return err
+	}
+
+	var buyer models.User
+	if err := tx.Select("id", "name", "email").First(&buyer, order.BuyerID).Error; err != nil {
+		return err
+	}
+	sellerName := DealershipName
+	if car.OwnerID != nil {
+		var seller models.User
+		if err := tx.Select("id", "name").First(&seller, *car.OwnerID).Error; err != nil {
+			return err
+		}
+		sellerName = seller.Name
+	}
+
+	invoice := models.Invoice{
+		OrderID:        order.ID,
+		Number:         fmt.Sprintf("INV-%d-%06d", year, seq.Last),
+		Amount:         order.Price,
+		Currency:       order.Currency,
+		BuyerName:      buyer.Name,
+		BuyerEmail:     buyer.Email,
+		SellerName:     sellerName,
+		CarDescription: fmt.Sprintf("%d %s %s", car.Year, car.Brand, car.Model),
+		VIN:            car.VIN,
+		IssuedAt:       now,
+	}
+	if err := tx.Create(&invoice).Error; err != nil {
+		return err
+	}
+	order.Invoice = &invoice
+	return nil
+}
+
+// orderSide reports whether the user is the buyer or on the seller side of the order.
+// Admins are the seller side of dealership cars.
+func orderSide(order *models.Order, userID uint, admin bool) (buyer bool, seller bool) {
+	buyer = order.BuyerID == userID
+	if order.SellerID != nil {
+		return buyer, *order.SellerID == userID
+	}
+	return buyer, admin
+}
+
+// hasOpenOrderTx reports whether a car is held by an order that is not finished yet
+func hasOpenOrderTx(tx *gorm.DB, carID uint) (bool, error) {
+	var open int64
+	err := tx.Model(&models.Order{}).
+		Where("car_id = ? AND status IN ?", carID, []models.OrderStatus{models.OrderPending, models.OrderConfirmed, models.OrderPaid}).
+		Count(&open).Error
+	return open > 0, err
+}
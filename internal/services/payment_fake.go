@@ -0,0 +1,198 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Test payment methods understood by the fake provider
+const (
+	FakeMethodSuccess        = "fake_success"         // authorized at once
+	FakeMethodDecline        = "fake_decline"         // declined at once
+	FakeMethodDelayed        = "fake_delayed"         // pending, authorized by a webhook after the delay
+	FakeMethodDelayedDecline = "fake_delayed_decline" // pending, declined by a webhook after the delay
+)
+
+// FakePaymentProviderName is the name of the fake provider in payments and webhook URLs
+const FakePaymentProviderName = "fake"
+
+// fakeWebhookAttempts is how many times the fake provider delivers a webhook that was not answered with 2xx
+const fakeWebhookAttempts = 3
+
+// FakePaymentProvider is an in-process payment gateway for development and offline end-to-end testing.
+// Delayed confirmations are posted as signed webhooks to webhookURL, the same way a real gateway calls back.
+type FakePaymentProvider struct {
+	secret     string
+	delay      time.Duration
+	webhookURL string
+	client     *http.Client
+
+	mu      sync.Mutex
+	intents map[string]models.PaymentStatus
+}
+
+// NewFakePaymentProvider creates a fake provider that signs its webhooks with secret
+// and confirms delayed payments after delay
+func NewFakePaymentProvider(secret string, delay time.Duration, webhookURL string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		secret:     secret,
+		delay:      delay,
+		webhookURL: webhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		intents:    make(map[string]models.PaymentStatus),
+	}
+}
+
+// Name returns the provider name
+func (p *FakePaymentProvider) Name() string {
+	return FakePaymentProviderName
+}
+
+// CreateIntent starts a payment whose outcome is chosen by the test method
+func (p *FakePaymentProvider) CreateIntent(ctx context.Context, req PaymentIntentRequest) (*PaymentIntent, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New("amount must be positive")
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+	intent := &PaymentIntent{ID: "pi_fake_" + id}
+	switch req.Method {
+	case FakeMethodSuccess, "":
+		intent.Status = models.PaymentAuthorized
+	case FakeMethodDecline:
+		intent.Status = models.PaymentFailed
+		intent.FailureReason = "card declined"
+	case FakeMethodDelayed, FakeMethodDelayedDecline:
+		intent.Status = models.PaymentPending
+		outcome := PaymentWebhook{Type: "payment_intent.authorized", Status: models.PaymentAuthorized}
+		if req.Method == FakeMethodDelayedDecline {
+			outcome = PaymentWebhook{Type: "payment_intent.failed", Status: models.PaymentFailed, FailureReason: "card declined"}
+		}
+		time.AfterFunc(p.delay, func() { p.confirm(intent.ID, outcome) })
+	default:
+		intent.Status = models.PaymentFailed
+		intent.FailureReason = fmt.Sprintf("unknown test payment method %q", req.Method)
+	}
+
+	p.mu.Lock()
+	p.intents[intent.ID] = intent.Status
+	p.mu.Unlock()
+	return intent, nil
+}
+
+// Capture takes the money of an authorized intent
+func (p *FakePaymentProvider) Capture(ctx context.Context, intentID string) (*PaymentIntent, error) {
+	return p.move(intentID, models.PaymentAuthorized, models.PaymentCaptured)
+}
+
+// Refund returns the money of a captured intent
+func (p *FakePaymentProvider) Refund(ctx context.Context, intentID string) (*PaymentIntent, error) {
+	return p.move(intentID, models.PaymentCaptured, models.PaymentRefunded)
+}
+
+// VerifyWebhook checks the HMAC signature of a callback and parses it
+func (p *FakePaymentProvider) VerifyWebhook(payload []byte, signature string) (*PaymentWebhook, error) {
+	if err := VerifyWebhookSignature(p.secret, payload, signature, time.Now()); err != nil {
+		return nil, err
+	}
+	var hook PaymentWebhook
+	if err := json.Unmarshal(payload, &hook); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if hook.EventID == "" || hook.IntentID == "" || !hook.Status.IsValid() {
+		return nil, errors.New("invalid webhook payload: id, intent_id and status are required")
+	}
+	return &hook, nil
+}
+
+// move changes the state of an intent that is in the from state
+func (p *FakePaymentProvider) move(intentID string, from, to models.PaymentStatus) (*PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status, ok := p.intents[intentID]
+	if !ok {
+		return nil, fmt.Errorf("unknown payment intent %s", intentID)
+	}
+	if status != from {
+		return nil, fmt.Errorf("payment intent %s is %s, not %s", intentID, status, from)
+	}
+	p.intents[intentID] = to
+	return &PaymentIntent{ID: intentID, Status: to}, nil
+}
+
+// confirm settles a delayed intent and notifies the webhook URL
+func (p *FakePaymentProvider) confirm(intentID string, outcome PaymentWebhook) {
+	p.mu.Lock()
+	if p.intents[intentID] != models.PaymentPending {
+		p.mu.Unlock()
+		return
+	}
+	p.intents[intentID] = outcome.Status
+	p.mu.Unlock()
+
+	eventID, err := randomHex(8)
+	if err != nil {
+		log.Printf("fake payment webhook: %v", err)
+		return
+	}
+	outcome.EventID = "evt_fake_" + eventID
+	outcome.IntentID = intentID
+	payload, err := json.Marshal(outcome)
+	if err != nil {
+		log.Printf("fake payment webhook: %v", err)
+		return
+	}
+
+	// Как настоящий шлюз, повторяем доставку, пока сервер не ответит 2xx
+	for attempt := 1; attempt <= fakeWebhookAttempts; attempt++ {
+		if err := p.deliver(payload); err == nil {
+			return
+		} else if attempt == fakeWebhookAttempts {
+			log.Printf("fake payment webhook %s for %s was not delivered: %v", outcome.EventID, intentID, err)
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+}
+
+func (p *FakePaymentProvider) deliver(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, p.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(PaymentSignatureHeader, SignWebhook(p.secret, time.Now(), payload))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// randomHex returns n random bytes as a hex string
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
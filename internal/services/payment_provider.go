@@ -0,0 +1,114 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payment provider errors
+var (
+	ErrPaymentProvider         = errors.New("payment provider error")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+)
+
+// PaymentSignatureHeader carries the signature of provider webhooks
+const PaymentSignatureHeader = "X-Payment-Signature"
+
+// WebhookTolerance is how far the timestamp of a signed webhook may be from the current time
+const WebhookTolerance = 5 * time.Minute
+
+// PaymentProvider is a payment gateway. Implementations translate their own states into payment statuses.
+type PaymentProvider interface {
+	// Name identifies the provider in stored payments and in the webhook URL
+	Name() string
+	// CreateIntent starts a payment; the intent may be authorized right away, declined or left pending
+	// until the provider confirms it with a webhook
+	CreateIntent(ctx context.Context, req PaymentIntentRequest) (*PaymentIntent, error)
+	// Capture takes the money of an authorized intent
+	Capture(ctx context.Context, intentID string) (*PaymentIntent, error)
+	// Refund returns the money of a captured intent
+	Refund(ctx context.Context, intentID string) (*PaymentIntent, error)
+	// VerifyWebhook checks the signature of a callback and parses it
+	VerifyWebhook(payload []byte, signature string) (*PaymentWebhook, error)
+}
+
+// PaymentIntentRequest describes the payment a provider is asked to start
+type PaymentIntentRequest struct {
+	Reference   string // our reference shown in the provider's dashboard, e.g. the invoice number
+	Amount      float64
+	Currency    string
+	Method      string // provider-specific payment method or token
+	Description string
+}
+
+// PaymentIntent is the provider's view of a payment
+type PaymentIntent struct {
+	ID            string
+	Status        models.PaymentStatus
+	FailureReason string
+}
+
+// PaymentWebhook is a verified provider callback about an intent
+type PaymentWebhook struct {
+	EventID       string               `json:"id"`
+	Type          string               `json:"type"`
+	IntentID      string               `json:"intent_id"`
+	Status        models.PaymentStatus `json:"status"`
+	FailureReason string               `json:"failure_reason,omitempty"`
+}
+
+// SignWebhook signs a webhook payload with HMAC-SHA256 over "<timestamp>.<payload>".
+// The result has the form "t=<unix timestamp>,v1=<hex digest>".
+func SignWebhook(secret string, timestamp time.Time, payload []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + webhookDigest(secret, ts, payload)
+}
+
+// VerifyWebhookSignature checks a signature made by SignWebhook and rejects timestamps outside WebhookTolerance,
+// so a captured callback cannot be replayed later
+func VerifyWebhookSignature(secret string, payload []byte, signature string, now time.Time) error {
+	var ts, digest string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			digest = value
+		}
+	}
+	if ts == "" || digest == "" {
+		return ErrInvalidWebhookSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+	age := now.Sub(time.Unix(unix, 0))
+	if age > WebhookTolerance || age < -WebhookTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidWebhookSignature)
+	}
+
+	expected := webhookDigest(secret, ts, payload)
+	if !hmac.Equal([]byte(expected), []byte(digest)) {
+		return ErrInvalidWebhookSignature
+	}
+	return nil
+}
+
+func webhookDigest(secret, ts string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	const secret = "whsec_test"
+	payload := []byte(`{"id":"evt_1","intent_id":"pi_1","status":"authorized"}`)
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	digest := webhookDigest(secret, ts, payload)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", payload: payload, signature: SignWebhook(secret, now, payload)},
+		{name: "valid with spaces and extra parts", payload: payload, signature: "t=" + ts + ", v0=old, v1=" + digest},
+		{name: "within tolerance", payload: payload, signature: SignWebhook(secret, now.Add(-WebhookTolerance+time.Second), payload)},
+		{name: "bad digest", payload: payload, signature: "t=" + ts + ",v1=" + webhookDigest("other", ts, payload), wantErr: true},
+		{name: "tampered payload", payload: []byte(`{"id":"evt_1","intent_id":"pi_1","status":"captured"}`), signature: SignWebhook(secret, now, payload), wantErr: true},
+		{name: "timestamp not covered by digest", payload: payload, signature: "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + digest, wantErr: true},
+		{name: "too old", payload: payload, signature: SignWebhook(secret, now.Add(-WebhookTolerance-time.Second), payload), wantErr: true},
+		{name: "too far in the future", payload: payload, signature: SignWebhook(secret, now.Add(WebhookTolerance+time.Second), payload), wantErr: true},
+		{name: "missing timestamp", payload: payload, signature: "v1=" + digest, wantErr: true},
+		{name: "missing digest", payload: payload, signature: "t=" + ts, wantErr: true},
+		{name: "non-numeric timestamp", payload: payload, signature: "t=yesterday,v1=" + digest, wantErr: true},
+		{name: "empty", payload: payload, signature: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyWebhookSignature(secret, tt.payload, tt.signature, now)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Fatalf("got %v, want ErrInvalidWebhookSignature", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Payment errors
+var (
+	ErrPaymentNotFound      = errors.New("payment not found")
+	ErrUnknownProvider      = errors.New("unknown payment provider")
+	ErrOrderNotPayable      = errors.New("only confirmed orders can be paid")
+	ErrPaymentInProgress    = errors.New("the order already has a payment in progress")
+	ErrPaymentNotRefundable = errors.New("only captured payments can be refunded")
+)
+
+// PaymentService pays orders through a payment provider and applies its webhooks.
+// Every attempt is stored as a payment; a captured payment marks its order paid.
+type PaymentService struct {
+	db       *gorm.DB
+	provider PaymentProvider
+}
+
+// NewPaymentService creates a new instance of PaymentService
+func NewPaymentService(db *gorm.DB, provider PaymentProvider) *PaymentService {
+	return &PaymentService{db: db, provider: provider}
+}
+
+// StartPayment starts paying a confirmed order for its buyer. Authorized payments are captured at once;
+// pending ones are settled later by the provider's webhook.
+func (s *PaymentService) StartPayment(ctx context.Context, orderID, buyerID uint, method string) (*models.Payment, error) {
+	var payment models.Payment
+	var invoice models.Invoice
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		order, err := lockOrderTx(tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.BuyerID != buyerID {
+			return ErrOrderForbidden
+		}
+		if order.Status != models.OrderConfirmed {
+			return ErrOrderNotPayable
+		}
+
+		var active int64
+		err = tx.Model(&models.Payment{}).
+			Where("order_id = ? AND status IN ?", order.ID, []models.PaymentStatus{models.PaymentPending, models.PaymentAuthorized}).
+			Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return ErrPaymentInProgress
+		}
+		if err := tx.Where("order_id = ?", order.ID).First(&invoice).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		payment = models.Payment{
+			OrderID:  order.ID,
+			Provider: s.provider.Name(),
+			Method:   method,
+			Amount:   order.Price,
+			Currency: order.Currency,
+			Status:   models.PaymentPending,
+		}
+		return tx.Create(&payment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Провайдера вызываем вне транзакции, попытка уже сохранена
+	intent, err := s.provider.CreateIntent(ctx, PaymentIntentRequest{
+		Reference:   invoice.Number,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Method:      method,
+		Description: fmt.Sprintf("Order %d", orderID),
+	})
+	if err != nil {
+		s.failPayment(payment.ID, err.Error())
+		return nil, fmt.Errorf("%w: %v", ErrPaymentProvider, err)
+	}
+	if err := s.db.Model(&payment).Update("provider_ref", intent.ID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.applyIntent(ctx, payment.ID, intent.Status, intent.FailureReason); err != nil {
+		return nil, err
+	}
+	return s.getPayment(payment.ID)
+}
+
+// HandleWebhook verifies and applies a provider callback. Each event is applied once;
+// it reports whether the event had already been processed.
+func (s *PaymentService) HandleWebhook(ctx context.Context, provider string, payload []byte, signature string) (bool, error) {
+	if provider != s.provider.Name() {
+		return false, ErrUnknownProvider
+	}
+	hook, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		if errors.Is(err, ErrInvalidWebhookSignature) {
+			return false, err
+		}
+		return false, fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+
+	duplicate := false
+	var paymentID uint
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		event := models.PaymentWebhookEvent{
+			Provider:    provider,
+			EventID:     hook.EventID,
+			Type:        hook.Type,
+			ProviderRef: hook.IntentID,
+			Payload:     string(payload),
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			duplicate = true
+			return nil
+		}
+
+		// Если платёж не найден, откатываем запись события, чтобы провайдер смог доставить его повторно
+		var payment models.Payment
+		if err := tx.Where("provider = ? AND provider_ref = ?", provider, hook.IntentID).First(&payment).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPaymentNotFound
+			}
+			return err
+		}
+		err := setPaymentStatusTx(tx, payment.ID, hook.Status, hook.FailureReason, nil)
+		if errors.Is(err, models.ErrIllegalPaymentTransition) {
+			log.Printf("ignoring stale payment webhook %s for payment %d: %v", hook.EventID, payment.ID, err)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		paymentID = payment.ID
+		return nil
+	})
+	if err != nil || duplicate {
+		return duplicate, err
+	}
+
+	// Событие уже сохранено, повторная доставка не поможет; ошибку захвата только логируем
+	if paymentID != 0 && hook.Status == models.PaymentAuthorized {
+		if err := s.capture(ctx, paymentID); err != nil {
+			log.Printf("failed to capture payment %d: %v", paymentID, err)
+		}
+	}
+	return false, nil
+}
+
+// RefundPayment returns the money of a captured payment. Refunding the payment of an order that
+// was not delivered yet cancels the order and puts the car back on sale.
+func (s *PaymentService) RefundPayment(ctx context.Context, id, actorID uint) (*models.Payment, error) {
+	payment, err := s.getPayment(id)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != models.PaymentCaptured {
+		return nil, ErrPaymentNotRefundable
+	}
+	if payment.Provider != s.provider.Name() {
+		return nil, ErrUnknownProvider
+	}
+
+	intent, err := s.provider.Refund(ctx, payment.ProviderRef)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPaymentProvider, err)
+	}
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		return setPaymentStatusTx(tx, id, intent.Status, intent.FailureReason, &actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.getPayment(id)
+}
+
+// GetOrderPayments returns every payment attempt of an order to its buyer, seller or an admin, oldest first
+func (s *PaymentService) GetOrderPayments(orderID, userID uint, admin bool) ([]models.Payment, error) {
+	var order models.Order
+	if err := s.db.Select("id", "buyer_id", "seller_id").First(&order, orderID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrderNotFound
+		}
+		return nil, err
+	}
+	if buyer, seller := orderSide(&order, userID, admin); !buyer && !seller && !admin {
+		return nil, ErrOrderForbidden
+	}
+
+	payments := []models.Payment{}
+	if err := s.db.Where("order_id = ?", orderID).Order("id").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// ListPayments returns a page of all payments for admins, optionally filtered by status and order, newest first
+func (s *PaymentService) ListPayments(status models.PaymentStatus, orderID uint, page, perPage int) ([]models.Payment, int64, error) {
+	query := s.db.Model(&models.Payment{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if orderID != 0 {
+		query = query.Where("order_id = ?", orderID)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	payments := []models.Payment{}
+	if total == 0 {
+		return payments, 0, nil
+	}
+
+	err := query.Order("id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&payments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return payments, total, nil
+}
+
+// applyIntent records the provider's status of a payment and captures it once it is authorized
+func (s *PaymentService) applyIntent(ctx context.Context, paymentID uint, status models.PaymentStatus, reason string) error {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return setPaymentStatusTx(tx, paymentID, status, reason, nil)
+	})
+	if err != nil {
+		return err
+	}
+	if status == models.PaymentAuthorized {
+		return s.capture(ctx, paymentID)
+	}
+	return nil
+}
+
+// capture takes the money of an authorized payment if its order is still waiting for it.
+// An order cancelled in the meantime leaves the authorization uncaptured and the payment failed.
+func (s *PaymentService) capture(ctx context.Context, paymentID uint) error {
+	var payment models.Payment
+	if err := s.db.First(&payment, paymentID).Error; err != nil {
+		return err
+	}
+	var order models.Order
+	if err := s.db.Select("id", "status").First(&order, payment.OrderID).Error; err != nil {
+		return err
+	}
+	if order.Status != models.OrderConfirmed {
+		s.failPayment(payment.ID, fmt.Sprintf("order is %s", order.Status))
+		return nil
+	}
+
+	intent, err := s.provider.Capture(ctx, payment.ProviderRef)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPaymentProvider, err)
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return setPaymentStatusTx(tx, payment.ID, intent.Status, intent.FailureReason, nil)
+	})
+}
+
+// failPayment marks an attempt failed outside of a provider status change
+func (s *PaymentService) failPayment(paymentID uint, reason string) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		return setPaymentStatusTx(tx, paymentID, models.PaymentFailed, reason, nil)
+	})
+	if err != nil {
+		log.Printf("failed to mark payment %d failed: %v", paymentID, err)
+	}
+}
+
+func (s *PaymentService) getPayment(id uint) (*models.Payment, error) {
+	var payment models.Payment
+	if err := s.db.First(&payment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// setPaymentStatusTx moves a payment to a new status and updates its order: a capture marks a confirmed
+// order paid, a refund cancels an order that was not delivered. A repeated status is a no-op.
+func setPaymentStatusTx(tx *gorm.DB, paymentID uint, to models.PaymentStatus, reason string, actorID *uint) error {
+	var current models.Payment
+	if err := tx.Select("id", "order_id").First(&current, paymentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrPaymentNotFound
+		}
+		return err
+	}
+	// Порядок блокировок: машина, заказ, платёж
+	order, err := lockOrderTx(tx, current.OrderID)
+	if err != nil {
+		return err
+	}
+	var payment models.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payment, paymentID).Error; err != nil {
+		return err
+	}
+	if payment.Status == to {
+		return nil
+	}
+	if err := payment.Status.CanTransitionTo(to); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": to}
+	switch to {
+	case models.PaymentFailed:
+		updates["failure_reason"] = reason
+	case models.PaymentCaptured:
+		updates["captured_at"] = now
+		if order.Status == models.OrderConfirmed {
+			if err := applyOrderStatusTx(tx, order, models.OrderPaid, nil, ""); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("payment %d captured for order %d in status %s", payment.ID, order.ID, order.Status)
+		}
+	case models.PaymentRefunded:
+		updates["refunded_at"] = now
+		updates["refunded_by"] = actorID
+		if order.Status == models.OrderPaid {
+			if err := applyOrderStatusTx(tx, order, models.OrderCancelled, actorID, "payment refunded"); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Model(&payment).Updates(updates).Error
+}
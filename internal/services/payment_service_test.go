@@ -0,0 +1,118 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// TestPaymentWebhookAppliedOnce walks an order through a delayed payment: the authorization webhook
+// captures the payment and marks the order paid, a refund cancels the order, and redeliveries of
+// the webhook are recognised as duplicates and change nothing.
+func TestPaymentWebhookAppliedOnce(t *testing.T) {
+	db := openTestDB(t, "users", "cars", "payment_webhook_events")
+	ctx := context.Background()
+
+	admin := models.User{Name: "Admin", Email: "admin@example.com", Password: "x", Role: "ADMIN"}
+	buyer := models.User{Name: "Buyer", Email: "buyer@example.com", Password: "x"}
+	car := models.Car{Brand: "Toyota", Model: "Camry", CarType: "sedan", Year: 2020, Price: 20000, Status: models.StatusAvailable}
+	for _, record := range []interface{}{&admin, &buyer, &car} {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	orders := NewOrderService(db)
+	order, err := orders.CreateOrder(buyer.ID, false, car.ID)
+	if err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+	if _, err := orders.UpdateOrderStatus(order.ID, admin.ID, true, models.OrderConfirmed, ""); err != nil {
+		t.Fatalf("confirm order: %v", err)
+	}
+
+	const secret = "whsec_test"
+	provider := NewFakePaymentProvider(secret, time.Hour, "")
+	payments := NewPaymentService(db, provider)
+	payment, err := payments.StartPayment(ctx, order.ID, buyer.ID, FakeMethodDelayed)
+	if err != nil {
+		t.Fatalf("start payment: %v", err)
+	}
+	if payment.Status != models.PaymentPending {
+		t.Fatalf("payment is %s, want pending", payment.Status)
+	}
+
+	// Шлюз подтверждает оплату на своей стороне и присылает webhook
+	provider.mu.Lock()
+	provider.intents[payment.ProviderRef] = models.PaymentAuthorized
+	provider.mu.Unlock()
+	payload, err := json.Marshal(PaymentWebhook{
+		EventID:  "evt_authorized",
+		Type:     "payment_intent.authorized",
+		IntentID: payment.ProviderRef,
+		Status:   models.PaymentAuthorized,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deliver := func() bool {
+		t.Helper()
+		duplicate, err := payments.HandleWebhook(ctx, provider.Name(), payload, SignWebhook(secret, time.Now(), payload))
+		if err != nil {
+			t.Fatalf("handle webhook: %v", err)
+		}
+		return duplicate
+	}
+	expect := func(wantPayment models.PaymentStatus, wantOrder models.OrderStatus) {
+		t.Helper()
+		var p models.Payment
+		if err := db.First(&p, payment.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		var o models.Order
+		if err := db.First(&o, order.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		if p.Status != wantPayment || o.Status != wantOrder {
+			t.Fatalf("payment %s, order %s; want payment %s, order %s", p.Status, o.Status, wantPayment, wantOrder)
+		}
+	}
+
+	if deliver() {
+		t.Fatal("first delivery reported as a duplicate")
+	}
+	expect(models.PaymentCaptured, models.OrderPaid)
+
+	if !deliver() {
+		t.Fatal("redelivery not reported as a duplicate")
+	}
+	expect(models.PaymentCaptured, models.OrderPaid)
+
+	if _, err := payments.RefundPayment(ctx, payment.ID, admin.ID); err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	expect(models.PaymentRefunded, models.OrderCancelled)
+
+	// Поздняя повторная доставка не должна снова провести уже возвращённый платёж
+	if !deliver() {
+		t.Fatal("late redelivery not reported as a duplicate")
+	}
+	expect(models.PaymentRefunded, models.OrderCancelled)
+
+	var events int64
+	if err := db.Model(&models.PaymentWebhookEvent{}).Count(&events).Error; err != nil {
+		t.Fatal(err)
+	}
+	if events != 1 {
+		t.Fatalf("stored %d webhook events, want 1", events)
+	}
+	var released models.Car
+	if err := db.First(&released, car.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if released.Status != models.StatusAvailable {
+		t.Fatalf("car is %s after the refund, want available", released.Status)
+	}
+}
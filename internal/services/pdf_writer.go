@@ -0,0 +1,105 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// pdfPage builds a single A4 page drawn with the standard Helvetica fonts, so no font files are embedded.
+// The standard fonts only cover WinAnsi, therefore Cyrillic text is transliterated and other
+// characters outside Latin-1 are replaced with '?'.
+type pdfPage struct {
+	content bytes.Buffer
+}
+
+// A4 page size in points
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+)
+
+// Text draws s with its baseline starting at (x, y); the origin is the bottom-left corner of the page
+func (p *pdfPage) Text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// Line draws a thin line from (x1, y1) to (x2, y2)
+func (p *pdfPage) Line(x1, y1, x2, y2 float64) {
+	fmt.Fprintf(&p.content, "0.5 w %.2f %.2f m %.2f %.2f l S\n", x1, y1, x2, y2)
+}
+
+// WriteTo writes the page as a complete PDF document
+func (p *pdfPage) WriteTo(w io.Writer) (int64, error) {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 4 0 R /F2 5 0 R >> >> /Contents 6 0 R >>", pdfPageWidth, pdfPageHeight),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()),
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buf.WriteTo(w)
+}
+
+// pdfTranslit maps Russian and Kazakh Cyrillic letters to Latin
+var pdfTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh", 'з': "z",
+	'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ә': "a", 'ғ': "g", 'қ': "q", 'ң': "n", 'ө': "o", 'ұ': "u", 'ү': "u", 'һ': "h", 'і': "i",
+}
+
+// pdfString encodes s as the body of a PDF literal string in WinAnsi
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if latin, ok := pdfTranslit[unicode.ToLower(r)]; ok {
+			if unicode.IsUpper(r) && latin != "" {
+				latin = strings.ToUpper(latin[:1]) + latin[1:]
+			}
+			b.WriteString(latin)
+			continue
+		}
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			// Latin-1 совпадает с WinAnsi в этом диапазоне
+			fmt.Fprintf(&b, "\\%03o", r)
+		case r == '–' || r == '—':
+			b.WriteByte('-')
+		case unicode.IsSpace(r):
+			b.WriteByte(' ')
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
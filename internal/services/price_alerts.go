@@ -0,0 +1,89 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// evaluatePriceAlertsTx notifies users whose favorite alerts are triggered by a price change.
+// any_drop fires on every reduction; below_target fires once when the price reaches the target
+// and again only after a further reduction or after the price went back above the target.
+// A target set while the car was priced in another currency is compared at the current exchange rates.
+func evaluatePriceAlertsTx(tx *gorm.DB, carID uint, oldPrice, newPrice float64) error {
+	var favorites []models.Favorite
+	if err := tx.Where("car_id = ? AND alert_type <> ''", carID).Find(&favorites).Error; err != nil {
+		return err
+	}
+	if len(favorites) == 0 {
+		return nil
+	}
+
+	var car models.Car
+	if err := tx.Select("id", "brand", "model", "year", "currency").First(&car, carID).Error; err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s %s %d", car.Brand, car.Model, car.Year)
+	rates, err := exchangeRates(tx)
+	if err != nil {
+		return err
+	}
+	basePrice := toBaseCurrency(newPrice, car.Currency, rates)
+
+	for _, fav := range favorites {
+		var notification *models.Notification
+		switch fav.AlertType {
+		case models.AlertAnyDrop:
+			if newPrice < oldPrice {
+				notification = priceDropNotification(fav.UserID, carID,
+					fmt.Sprintf("%s is now cheaper", name),
+					fmt.Sprintf("The price dropped from %.2f to %.2f.", oldPrice, newPrice))
+			}
+		case models.AlertBelowTarget:
+			if fav.AlertTargetPrice == nil {
+				continue
+			}
+			target, targetCurrency := *fav.AlertTargetPrice, fav.AlertTargetCurrency
+			if targetCurrency == "" {
+				targetCurrency = car.Currency
+			}
+			if basePrice > toBaseCurrency(target, targetCurrency, rates) {
+				// Цена снова выше цели — следующее снижение до цели должно уведомить заново
+				if fav.AlertNotifiedPrice != nil {
+					if err := tx.Model(&fav).Update("alert_notified_price", nil).Error; err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if fav.AlertNotifiedPrice != nil && newPrice >= *fav.AlertNotifiedPrice {
+				continue
+			}
+			notification = priceDropNotification(fav.UserID, carID,
+				fmt.Sprintf("%s reached your target price", name),
+				fmt.Sprintf("The price is now %.2f %s, your target was %.2f %s.", newPrice, car.Currency, target, targetCurrency))
+			if err := tx.Model(&fav).Update("alert_notified_price", newPrice).Error; err != nil {
+				return err
+			}
+		}
+
+		if notification != nil {
+			if err := tx.Create(notification).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func priceDropNotification(userID, carID uint, title, message string) *models.Notification {
+	return &models.Notification{
+		UserID:  userID,
+		Type:    models.NotificationPriceDrop,
+		Title:   title,
+		Message: message,
+		CarID:   &carID,
+	}
+}
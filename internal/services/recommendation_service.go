@@ -0,0 +1,356 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Limits of GET /api/recommendations
+const (
+	DefaultRecommendations = 10
+	MaxRecommendations     = 50
+)
+
+// Profile tuning
+const (
+	// HighRatingThreshold is the lowest review rating that counts as a liked car
+	HighRatingThreshold = 4
+	// profileTopN is the number of brands and car types kept in a profile
+	profileTopN = 3
+	// profilePriceMargin widens the liked price range on both sides
+	profilePriceMargin = 0.2
+)
+
+// Feed sources
+const (
+	FeedPersonalized = "personalized"
+	FeedTopRated     = "top_rated"
+)
+
+// Weights of the profile signals in the recommendation score
+const (
+	recommendBrandWeight = 3.0
+	recommendTypeWeight  = 2.0
+	recommendPriceWeight = 2.0
+	recommendMaxScore    = recommendBrandWeight + recommendTypeWeight + recommendPriceWeight
+)
+
+// ProfilePreference is a brand or car type the user likes, with its share of the signals
+type ProfilePreference struct {
+	Value string  `json:"value"`
+	Count int     `json:"count"`
+	Share float64 `json:"share"`
+}
+
+// PreferenceProfile summarizes what a user likes, built from favorites and high-rated reviews
+type PreferenceProfile struct {
+	Signals  int                 `json:"signals"`
+	Brands   []ProfilePreference `json:"brands"`
+	CarTypes []ProfilePreference `json:"car_types"`
+	PriceMin float64             `json:"price_min"`
+	PriceMax float64             `json:"price_max"`
+	Currency string              `json:"currency"` // the price range is in the base currency
+
+	excluded []uint             // cars the user already favorited or reviewed
+	rates    map[string]float64 // exchange rates for matching prices against the range
+}
+
+// Recommendation is a recommended car with the reasons it was picked
+type Recommendation struct {
+	Car     models.Car `json:"car"`
+	Score   float64    `json:"score"` // 0..1, zero for top rated fallback cars
+	Reasons []string   `json:"reasons"`
+}
+
+// RecommendationFeed is the response of GET /api/recommendations
+type RecommendationFeed struct {
+	Source  string             `json:"source"`
+	Profile *PreferenceProfile `json:"profile,omitempty"`
+	Items   []Recommendation   `json:"items"`
+}
+
+type RecommendationService struct {
+	db      *gorm.DB
+	reviews *ReviewService
+}
+
+func NewRecommendationService(db *gorm.DB, reviewService *ReviewService) *RecommendationService {
+	return &RecommendationService{db: db, reviews: reviewService}
+}
+
+// GetRecommendations returns cars matching the user's profile that the user has not favorited or reviewed.
+// Users without favorites or high ratings get the top rated cars; a short personalized feed is topped up with them.
+func (s *RecommendationService) GetRecommendations(userID uint, limit int) (*RecommendationFeed, error) {
+	if limit < 1 || limit > MaxRecommendations {
+		limit = DefaultRecommendations
+	}
+
+	profile, err := s.BuildProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &RecommendationFeed{Source: FeedTopRated, Items: []Recommendation{}}
+	if profile.Signals > 0 {
+		feed.Source = FeedPersonalized
+		feed.Profile = profile
+		if feed.Items, err = s.personalized(profile, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(feed.Items) < limit {
+		if err := s.fillTopRated(feed, profile.excluded, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return feed, nil
+}
+
+// BuildProfile collects the brands, car types and price range of the user's favorites and high-rated reviews.
+// The price range is in the base currency so that cars priced in any currency can be matched against it.
+func (s *RecommendationService) BuildProfile(userID uint) (*PreferenceProfile, error) {
+	var favoriteIDs, likedIDs, reviewedIDs []uint
+	if err := s.db.Model(&models.Favorite{}).Where("user_id = ?", userID).Pluck("car_id", &favoriteIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&models.Review{}).Where("user_id = ? AND rating >= ?", userID, HighRatingThreshold).Pluck("car_id", &likedIDs).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Model(&models.Review{}).Where("user_id = ?", userID).Pluck("car_id", &reviewedIDs).Error; err != nil {
+		return nil, err
+	}
+
+	rates, err := exchangeRates(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &PreferenceProfile{
+		Currency: models.BaseCurrency,
+		excluded: uniqueIDs(append(append(favoriteIDs, reviewedIDs...), likedIDs...)),
+		rates:    rates,
+	}
+	signalIDs := uniqueIDs(append(favoriteIDs, likedIDs...))
+	if len(signalIDs) == 0 {
+		return profile, nil
+	}
+
+	var cars []models.Car
+	if err := s.db.Where("id IN ?", signalIDs).Find(&cars).Error; err != nil {
+		return nil, err
+	}
+	if len(cars) == 0 {
+		return profile, nil
+	}
+
+	brands := make(map[string]*ProfilePreference)
+	types := make(map[string]*ProfilePreference)
+	profile.PriceMin, profile.PriceMax = math.MaxFloat64, 0
+	for _, car := range cars {
+		countPreference(brands, car.Brand)
+		countPreference(types, car.CarType)
+		if car.Price > 0 {
+			price := toBaseCurrency(car.Price, car.Currency, rates)
+			profile.PriceMin = math.Min(profile.PriceMin, price)
+			profile.PriceMax = math.Max(profile.PriceMax, price)
+		}
+	}
+
+	profile.Signals = len(cars)
+	profile.Brands = topPreferences(brands, len(cars))
+	profile.CarTypes = topPreferences(types, len(cars))
+	if profile.PriceMax == 0 {
+		profile.PriceMin = 0
+	} else {
+		profile.PriceMin = math.Round(profile.PriceMin * (1 - profilePriceMargin))
+		profile.PriceMax = math.Round(profile.PriceMax * (1 + profilePriceMargin))
+	}
+
+	return profile, nil
+}
+
+// personalized ranks candidate cars by how well they match the profile
+func (s *RecommendationService) personalized(profile *PreferenceProfile, limit int) ([]Recommendation, error) {
+	var terms, filters []string
+	var args, filterArgs []interface{}
+	for _, b := range profile.Brands {
+		terms = append(terms, "CASE WHEN LOWER(brand) = ? THEN "+floatParam+" ELSE 0.0 END")
+		args = append(args, b.Value, recommendBrandWeight*b.Share)
+		filters = append(filters, "LOWER(brand) = ?")
+		filterArgs = append(filterArgs, b.Value)
+	}
+	for _, t := range profile.CarTypes {
+		terms = append(terms, "CASE WHEN LOWER(car_type) = ? THEN "+floatParam+" ELSE 0.0 END")
+		args = append(args, t.Value, recommendTypeWeight*t.Share)
+		filters = append(filters, "LOWER(car_type) = ?")
+		filterArgs = append(filterArgs, t.Value)
+	}
+	if profile.PriceMax > 0 {
+		terms = append(terms, "CASE WHEN "+carBasePriceSQL+" BETWEEN ? AND ? THEN "+floatParam+" ELSE 0.0 END")
+		args = append(args, profile.PriceMin, profile.PriceMax, recommendPriceWeight)
+		filters = append(filters, carBasePriceSQL+" BETWEEN ? AND ?")
+		filterArgs = append(filterArgs, profile.PriceMin, profile.PriceMax)
+	}
+	if len(terms) == 0 {
+		return []Recommendation{}, nil
+	}
+
+	query := s.db.Model(&models.Car{}).
+		Select("id, ("+strings.Join(terms, " + ")+") AS score", args...).
+		Where("status <> ?", models.StatusSold).
+		Where(strings.Join(filters, " OR "), filterArgs...)
+	if len(profile.excluded) > 0 {
+		query = query.Where("id NOT IN ?", profile.excluded)
+	}
+
+	var ranked []struct {
+		ID    uint
+		Score float64
+	}
+	if err := query.Order("score DESC, avg_rating DESC, id ASC").Limit(limit).Scan(&ranked).Error; err != nil {
+		return nil, err
+	}
+
+	items := []Recommendation{}
+	if len(ranked) == 0 {
+		return items, nil
+	}
+
+	ids := make([]uint, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	var cars []models.Car
+	if err := s.db.Where("id IN ?", ids).Find(&cars).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Car, len(cars))
+	for _, car := range cars {
+		byID[car.ID] = car
+	}
+
+	for _, r := range ranked {
+		car, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		items = append(items, Recommendation{
+			Car:     car,
+			Score:   roundTo(r.Score/recommendMaxScore, 3),
+			Reasons: profile.reasons(&car),
+		})
+	}
+
+	return items, nil
+}
+
+// fillTopRated appends top rated cars the user has not seen yet until the feed has limit items
+func (s *RecommendationService) fillTopRated(feed *RecommendationFeed, excluded []uint, limit int) error {
+	skip := make(map[uint]bool, len(excluded)+len(feed.Items))
+	for _, id := range excluded {
+		skip[id] = true
+	}
+	for _, item := range feed.Items {
+		skip[item.Car.ID] = true
+	}
+
+	top, err := s.reviews.GetTopRatedCars(limit + len(skip))
+	if err != nil {
+		return err
+	}
+
+	for _, car := range top {
+		if len(feed.Items) == limit {
+			break
+		}
+		if skip[car.ID] || car.Status == models.StatusSold {
+			continue
+		}
+		reason := "Popular with other buyers"
+		if car.AvgRating > 0 {
+			reason = fmt.Sprintf("Top rated: %.1f out of 5", car.AvgRating)
+		}
+		feed.Items = append(feed.Items, Recommendation{Car: car, Reasons: []string{reason}})
+	}
+
+	return nil
+}
+
+// reasons explains which parts of the profile a car matches
+func (p *PreferenceProfile) reasons(car *models.Car) []string {
+	var reasons []string
+	for _, b := range p.Brands {
+		if strings.EqualFold(car.Brand, b.Value) {
+			reasons = append(reasons, fmt.Sprintf("You liked %d %s %s", b.Count, car.Brand, pluralCars(b.Count)))
+		}
+	}
+	for _, t := range p.CarTypes {
+		if strings.EqualFold(car.CarType, t.Value) {
+			reasons = append(reasons, fmt.Sprintf("You often choose the %s body type", car.CarType))
+		}
+	}
+	price := toBaseCurrency(car.Price, car.Currency, p.rates)
+	if p.PriceMax > 0 && price >= p.PriceMin && price <= p.PriceMax {
+		reasons = append(reasons, fmt.Sprintf("In your price range of %.0f–%.0f %s", p.PriceMin, p.PriceMax, p.Currency))
+	}
+	if car.AvgRating >= HighRatingThreshold {
+		reasons = append(reasons, fmt.Sprintf("Highly rated: %.1f out of 5", car.AvgRating))
+	}
+	return reasons
+}
+
+func countPreference(counts map[string]*ProfilePreference, value string) {
+	key := strings.ToLower(strings.TrimSpace(value))
+	if key == "" {
+		return
+	}
+	if counts[key] == nil {
+		counts[key] = &ProfilePreference{Value: key}
+	}
+	counts[key].Count++
+}
+
+// topPreferences returns the most frequent values, most frequent first
+func topPreferences(counts map[string]*ProfilePreference, total int) []ProfilePreference {
+	prefs := make([]ProfilePreference, 0, len(counts))
+	for _, p := range counts {
+		p.Share = roundTo(float64(p.Count)/float64(total), 3)
+		prefs = append(prefs, *p)
+	}
+	sort.Slice(prefs, func(i, j int) bool {
+		if prefs[i].Count != prefs[j].Count {
+			return prefs[i].Count > prefs[j].Count
+		}
+		return prefs[i].Value < prefs[j].Value
+	})
+	if len(prefs) > profileTopN {
+		prefs = prefs[:profileTopN]
+	}
+	return prefs
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func pluralCars(n int) string {
+	if n == 1 {
+		return "car"
+	}
+	return "cars"
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultReservationHold is how long a car stays reserved unless configured otherwise
+const DefaultReservationHold = 48 * time.Hour
+
+// Reservation errors
+var (
+	ErrReservationNotFound  = errors.New("reservation not found")
+	ErrCarNotAvailable      = errors.New("car is not available for reservation")
+	ErrReservationNotActive = errors.New("reservation is not active")
+	ErrReservationForbidden = errors.New("not authorized to manage this reservation")
+)
+
+// ReservationService handles time-boxed car reservations
+type ReservationService struct {
+	db   *gorm.DB
+	hold time.Duration
+}
+
+// NewReservationService creates a new instance of ReservationService with the given hold window
+func NewReservationService(db *gorm.DB, hold time.Duration) *ReservationService {
+	if hold <= 0 {
+		hold = DefaultReservationHold
+	}
+	return &ReservationService{db: db, hold: hold}
+}
+
+// HoldWindow returns how long new reservations are held
+func (s *ReservationService) HoldWindow() time.Duration {
+	return s.hold
+}
+
+// ReserveCar places a hold on an available car for the user.
+// The car row is locked and moved to the reservation status in the same transaction,
+// so two concurrent requests cannot both reserve the same car.
+func (s *ReservationService) ReserveCar(userID, carID uint) (*models.Reservation, error) {
+	var reservation *models.Reservation
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		reservation, err = s.reserveCarTx(tx, userID, carID, fmt.Sprintf("reserved by user %d", userID))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.db.Preload("Car").First(reservation, reservation.ID)
+	return reservation, nil
+}
+
+// reserveCarTx moves an available car to the reservation status and holds it for the user
+// inside an existing transaction
+func (s *ReservationService) reserveCarTx(tx *gorm.DB, userID, carID uint, reason string) (*models.Reservation, error) {
+	if _, err := changeCarStatusTx(tx, carID, models.StatusReservation, &userID, reason, false); err != nil {
+		if errors.Is(err, models.ErrIllegalStatusTransition) {
+			return nil, ErrCarNotAvailable
+		}
+		return nil, err
+	}
+
+	reservation := models.Reservation{
+		CarID:     carID,
+		UserID:    userID,
+		Status:    models.ReservationActive,
+		ExpiresAt: time.Now().Add(s.hold),
+	}
+	if err := tx.Create(&reservation).Error; err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetReservationByID retrieves a reservation by its ID
+func (s *ReservationService) GetReservationByID(id uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+	if err := s.db.Preload("Car").First(&reservation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// GetUserReservations returns the reservations of a user, optionally filtered by status
+func (s *ReservationService) GetUserReservations(userID uint, status models.ReservationStatus) ([]models.Reservation, error) {
+	query := s.db.Preload("Car").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	reservations := []models.Reservation{}
+	if err := query.Order("created_at DESC").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// ListReservations returns all reservations for admins, optionally filtered by status and car
+func (s *ReservationService) ListReservations(status models.ReservationStatus, carID uint) ([]models.Reservation, error) {
+	query := s.db.Preload("Car")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if carID != 0 {
+		query = query.Where("car_id = ?", carID)
+	}
+
+	reservations := []models.Reservation{}
+	if err := query.Order("created_at DESC").Find(&reservations).Error; err != nil {
+		return nil, err
+	}
+	return reservations, nil
+}
+
+// CancelReservation cancels an active reservation and returns the car to available.
+// Only the user who made the reservation or an admin may cancel it.
+func (s *ReservationService) CancelReservation(id, actorID uint, admin bool) (*models.Reservation, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var reservation models.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReservationNotFound
+			}
+			return err
+		}
+
+		if !admin && reservation.UserID != actorID {
+			return ErrReservationForbidden
+		}
+		if reservation.Status != models.ReservationActive {
+			return ErrReservationNotActive
+		}
+
+		now := time.Now()
+		if err := tx.Model(&reservation).Updates(map[string]interface{}{
+			"status":       models.ReservationCancelled,
+			"cancelled_by": actorID,
+			"cancelled_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		return releaseReservedCarTx(tx, reservation.CarID, &actorID, fmt.Sprintf("reservation %d cancelled", reservation.ID))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetReservationByID(id)
+}
+
+// ExpireStaleReservations marks reservations past their hold window as expired
+// and returns their cars to available. It returns the number of expired reservations.
+func (s *ReservationService) ExpireStaleReservations(now time.Time) (int, error) {
+	var stale []models.Reservation
+	if err := s.db.Where("status = ? AND expires_at <= ?", models.ReservationActive, now).Find(&stale).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, reservation := range stale {
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			// Бронь могли отменить параллельно, поэтому обновляем только активную
+			result := tx.Model(&models.Reservation{}).
+				Where("id = ? AND status = ?", reservation.ID, models.ReservationActive).
+				Update("status", models.ReservationExpired)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			expired++
+			return releaseReservedCarTx(tx, reservation.CarID, nil, fmt.Sprintf("reservation %d expired", reservation.ID))
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// StartExpiryWorker periodically expires stale reservations until the context is cancelled
+func (s *ReservationService) StartExpiryWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			count, err := s.ExpireStaleReservations(time.Now())
+			if err != nil {
+				log.Printf("reservation expiry failed: %v", err)
+				continue
+			}
+			if count > 0 {
+				log.Printf("expired %d reservation(s)", count)
+			}
+		}
+	}
+}
+
+// releaseReservedCarTx returns a reserved car to available.
+// Cars that have since moved on (e.g. sold by an admin) are left untouched.
+func releaseReservedCarTx(tx *gorm.DB, carID uint, actorID *uint, reason string) error {
+	_, err := changeCarStatusTx(tx, carID, models.StatusAvailable, actorID, reason, false)
+	if err != nil && !errors.Is(err, models.ErrIllegalStatusTransition) && !errors.Is(err, ErrCarNotFound) {
+		return err
+	}
+	return nil
+}
+
+// cancelActiveReservationTx cancels the active reservation of a car, if any, and reports whether one was cancelled
+func cancelActiveReservationTx(tx *gorm.DB, carID uint, actorID *uint) (bool, error) {
+	result := tx.Model(&models.Reservation{}).
+		Where("car_id = ? AND status = ?", carID, models.ReservationActive).
+		Updates(map[string]interface{}{
+			"status":       models.ReservationCancelled,
+			"cancelled_by": actorID,
+			"cancelled_at": time.Now(),
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ReviewService handles business logic related to car reviews
@@ -30,6 +31,15 @@ func (s *ReviewService) CreateReview(review *models.Review) error {
 	}
 
 	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Cars in the trash cannot be reviewed
+		var car models.Car
+		if err := tx.Select("id").First(&car, review.CarID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+
 		// Create the review
 		if err := tx.Create(review).Error; err != nil {
 			return err
@@ -71,20 +81,37 @@ func (s *ReviewService) GetReviewByID(id uint) (*models.Review, error) {
 	return &review, nil
 }
 
-// UpdateReview updates an existing review and recalculates car's average rating
-func (s *ReviewService) UpdateReview(review *models.Review) error {
+// UpdateReview updates the rating and comment of an existing review and recalculates car's average rating.
+// The stored version must be allowed by match; on success review holds the saved review with its new version.
+func (s *ReviewService) UpdateReview(review *models.Review, match VersionMatch) error {
 	if err := review.Validate(); err != nil {
 		return err
 	}
 
 	return s.db.Transaction(func(tx *gorm.DB) error {
-		// Update the review
-		if err := tx.Save(review).Error; err != nil {
+		var current models.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, review.ID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return ErrReviewNotFound
 			}
 			return err
 		}
+		if !match.Allows(current.Version) {
+			return ErrVersionMismatch
+		}
+
+		// Update the review
+		err := tx.Model(&current).Updates(map[string]interface{}{
+			"rating":  review.Rating,
+			"comment": review.Comment,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Preload("Car").First(review, current.ID).Error; err != nil {
+			return err
+		}
 
 		// Recalculate car's average rating
 		var avgRating float32
@@ -105,17 +132,20 @@ func (s *ReviewService) UpdateReview(review *models.Review) error {
 	})
 }
 
-// DeleteReview removes a review and updates car's average rating
-func (s *ReviewService) DeleteReview(id uint) error {
-	var review models.Review
-	if err := s.db.First(&review, id).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrReviewNotFound
+// DeleteReview removes a review if its version is allowed by match and updates car's average rating
+func (s *ReviewService) DeleteReview(id uint, match VersionMatch) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var review models.Review
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReviewNotFound
+			}
+			return err
+		}
+		if !match.Allows(review.Version) {
+			return ErrVersionMismatch
 		}
-		return err
-	}
 
-	return s.db.Transaction(func(tx *gorm.DB) error {
 		// Delete the review
 		if err := tx.Delete(&review).Error; err != nil {
 			return err
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+)
+
+// ErrCarForbidden is returned when a seller changes a car listed by someone else
+var ErrCarForbidden = errors.New("not authorized to manage this car")
+
+// CarEditor is the user changing a car. Admins manage every car;
+// with OwnerOnly set the user may only change the cars they own.
+type CarEditor struct {
+	UserID    uint
+	OwnerOnly bool
+}
+
+// canManage reports whether the editor may change car
+func (e CarEditor) canManage(car *models.Car) bool {
+	if !e.OwnerOnly {
+		return true
+	}
+	return car.OwnerID != nil && *car.OwnerID == e.UserID
+}
+
+// CreateListing publishes a car on behalf of a private seller
+func CreateListing(car *models.Car, sellerID uint) error {
+	car.OwnerID = &sellerID
+	return CreateCar(car, sellerID)
+}
+
+// ListSellerCars returns a page of the cars owned by a seller, newest first. Sold cars are included.
+func ListSellerCars(sellerID uint, page, perPage int) ([]models.Car, int64, error) {
+	query := DB.Model(&models.Car{}).Where("owner_id = ?", sellerID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	cars := []models.Car{}
+	if total == 0 {
+		return cars, 0, nil
+	}
+
+	err := query.Preload("Owner").
+		Order("id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&cars).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return cars, total, nil
+}
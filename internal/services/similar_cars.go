@@ -0,0 +1,352 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Limits of GET /cars/:id/similar
+const (
+	DefaultSimilarCars = 10
+	MaxSimilarCars     = 50
+)
+
+// Distances at which a numeric attribute stops counting as similar
+const (
+	similarPriceBand    = 0.3 // ±30% of the listing price
+	similarYearRange    = 5
+	similarEngineRange  = 1.0 // litres
+	similarMileageRange = 50000
+)
+
+// SimilarityWeights sets how much each attribute contributes to the similarity score
+type SimilarityWeights struct {
+	CarType      float64
+	Brand        float64
+	Price        float64
+	Year         float64
+	EngineVolume float64
+	Transmission float64
+	Mileage      float64
+}
+
+// DefaultSimilarityWeights favours the body type and the price band
+var DefaultSimilarityWeights = SimilarityWeights{
+	CarType:      3,
+	Brand:        2,
+	Price:        3,
+	Year:         1.5,
+	EngineVolume: 1,
+	Transmission: 1,
+	Mileage:      1,
+}
+
+// ErrInvalidSimilarityWeights is returned for negative weights or weights that sum to zero
+var ErrInvalidSimilarityWeights = errors.New("similarity weights must be non-negative and not all zero")
+
+// ParseSimilarityWeights reads weights such as "price=4,brand=1" over DefaultSimilarityWeights.
+// The names are car_type, brand, price, year, engine_vol, transmission and mileage.
+func ParseSimilarityWeights(raw string) (SimilarityWeights, error) {
+	weights := DefaultSimilarityWeights
+	fields := map[string]*float64{
+		"car_type":     &weights.CarType,
+		"brand":        &weights.Brand,
+		"price":        &weights.Price,
+		"year":         &weights.Year,
+		"engine_vol":   &weights.EngineVolume,
+		"transmission": &weights.Transmission,
+		"mileage":      &weights.Mileage,
+	}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		field, known := fields[strings.TrimSpace(name)]
+		if !ok || !known {
+			return weights, fmt.Errorf("expected name=weight, got %q", part)
+		}
+		w, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return weights, fmt.Errorf("weight %q is not a number", value)
+		}
+		*field = w
+	}
+	return weights, nil
+}
+
+// similarityAttribute scores one attribute both in SQL (for ranking) and in Go (for the explanation).
+// Both sides see prices in the base currency, see inBaseCurrency.
+type similarityAttribute struct {
+	name   string
+	weight func(w SimilarityWeights) float64
+	sql    func(ref *models.Car) (string, []interface{})
+	score  func(ref, car *models.Car) float64
+}
+
+var similarityAttributes = []similarityAttribute{
+	{
+		name:   "car_type",
+		weight: func(w SimilarityWeights) float64 { return w.CarType },
+		sql:    func(ref *models.Car) (string, []interface{}) { return textMatchSQL("car_type", ref.CarType) },
+		score:  func(ref, car *models.Car) float64 { return textMatch(ref.CarType, car.CarType) },
+	},
+	{
+		name:   "brand",
+		weight: func(w SimilarityWeights) float64 { return w.Brand },
+		sql:    func(ref *models.Car) (string, []interface{}) { return textMatchSQL("brand", ref.Brand) },
+		score:  func(ref, car *models.Car) float64 { return textMatch(ref.Brand, car.Brand) },
+	},
+	{
+		name:   "price",
+		weight: func(w SimilarityWeights) float64 { return w.Price },
+		sql: func(ref *models.Car) (string, []interface{}) {
+			return closenessSQL(carBasePriceSQL, ref.Price, priceScale(ref))
+		},
+		score: func(ref, car *models.Car) float64 { return closeness(car.Price, ref.Price, priceScale(ref)) },
+	},
+	{
+		name:   "year",
+		weight: func(w SimilarityWeights) float64 { return w.Year },
+		sql: func(ref *models.Car) (string, []interface{}) {
+			return closenessSQL("year", float64(ref.Year), similarYearRange)
+		},
+		score: func(ref, car *models.Car) float64 {
+			return closeness(float64(car.Year), float64(ref.Year), similarYearRange)
+		},
+	},
+	{
+		name:   "engine_vol",
+		weight: func(w SimilarityWeights) float64 { return w.EngineVolume },
+		sql: func(ref *models.Car) (string, []interface{}) {
+			return closenessSQL("engine_volume", ref.EngineVolume, similarEngineRange)
+		},
+		score: func(ref, car *models.Car) float64 {
+			return closeness(car.EngineVolume, ref.EngineVolume, similarEngineRange)
+		},
+	},
+	{
+		name:   "transmission",
+		weight: func(w SimilarityWeights) float64 { return w.Transmission },
+		sql:    func(ref *models.Car) (string, []interface{}) { return textMatchSQL("transmission", ref.Transmission) },
+		score:  func(ref, car *models.Car) float64 { return textMatch(ref.Transmission, car.Transmission) },
+	},
+	{
+		name:   "mileage",
+		weight: func(w SimilarityWeights) float64 { return w.Mileage },
+		sql: func(ref *models.Car) (string, []interface{}) {
+			return closenessSQL("mileage", ref.Mileage, similarMileageRange)
+		},
+		score: func(ref, car *models.Car) float64 { return closeness(car.Mileage, ref.Mileage, similarMileageRange) },
+	},
+}
+
+// SimilarityFactor explains the contribution of one attribute to a score
+type SimilarityFactor struct {
+	Attribute    string      `json:"attribute"`
+	Value        interface{} `json:"value"`
+	Weight       float64     `json:"weight"`
+	Similarity   float64     `json:"similarity"`   // 0..1
+	Contribution float64     `json:"contribution"` // share of the total score
+}
+
+// SimilarCar is a car similar to a listing together with its score
+type SimilarCar struct {
+	Car     models.Car         `json:"car"`
+	Score   float64            `json:"score"` // 0..1
+	Factors []SimilarityFactor `json:"factors"`
+}
+
+type SimilarCarsService struct {
+	db      *gorm.DB
+	weights SimilarityWeights
+}
+
+func NewSimilarCarsService(db *gorm.DB, weights SimilarityWeights) (*SimilarCarsService, error) {
+	total := 0.0
+	for _, attr := range similarityAttributes {
+		w := attr.weight(weights)
+		if w < 0 {
+			return nil, ErrInvalidSimilarityWeights
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, ErrInvalidSimilarityWeights
+	}
+	return &SimilarCarsService{db: db, weights: weights}, nil
+}
+
+// GetSimilarCars returns up to limit cars most similar to the given one.
+// Candidates sharing the type, the brand or the price band are scored and ranked in the database,
+// so only the top rows are loaded.
+func (s *SimilarCarsService) GetSimilarCars(carID uint, limit int) ([]SimilarCar, error) {
+	if limit < 1 || limit > MaxSimilarCars {
+		limit = DefaultSimilarCars
+	}
+
+	var listing models.Car
+	if err := s.db.First(&listing, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	rates, err := exchangeRates(s.db)
+	if err != nil {
+		return nil, err
+	}
+	ref := inBaseCurrency(listing, rates)
+
+	var terms []string
+	var args []interface{}
+	total := 0.0
+	for _, attr := range similarityAttributes {
+		w := attr.weight(s.weights)
+		if w == 0 {
+			continue
+		}
+		expr, vars := attr.sql(&ref)
+		terms = append(terms, floatParam+" * "+expr)
+		args = append(append(args, w), vars...)
+		total += w
+	}
+	scoreSQL := "(" + strings.Join(terms, " + ") + ") / " + floatParam
+	args = append(args, total)
+
+	band := priceScale(&ref)
+	var ranked []struct {
+		ID    uint
+		Score float64
+	}
+	err = s.db.Model(&models.Car{}).
+		Select("id, "+scoreSQL+" AS score", args...).
+		Where("id <> ? AND status <> ?", ref.ID, models.StatusSold).
+		Where("LOWER(car_type) = LOWER(?) OR LOWER(brand) = LOWER(?) OR "+carBasePriceSQL+" BETWEEN ? AND ?",
+			ref.CarType, ref.Brand, ref.Price-band, ref.Price+band).
+		Order("score DESC, id ASC").
+		Limit(limit).
+		Scan(&ranked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := []SimilarCar{}
+	if len(ranked) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uint, len(ranked))
+	for i, r := range ranked {
+		ids[i] = r.ID
+	}
+	var cars []models.Car
+	if err := s.db.Where("id IN ?", ids).Find(&cars).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]models.Car, len(cars))
+	for _, car := range cars {
+		byID[car.ID] = car
+	}
+
+	for _, r := range ranked {
+		car, ok := byID[r.ID]
+		if !ok {
+			continue
+		}
+		result = append(result, s.explain(&ref, car, rates, total))
+	}
+
+	return result, nil
+}
+
+// explain recomputes the score of a car per attribute; the price factor shows the car's own price
+func (s *SimilarCarsService) explain(ref *models.Car, car models.Car, rates map[string]float64, total float64) SimilarCar {
+	similar := SimilarCar{Car: car, Factors: make([]SimilarityFactor, 0, len(similarityAttributes))}
+	values := map[string]interface{}{
+		"car_type":     car.CarType,
+		"brand":        car.Brand,
+		"price":        car.Price,
+		"year":         car.Year,
+		"engine_vol":   car.EngineVolume,
+		"transmission": car.Transmission,
+		"mileage":      car.Mileage,
+	}
+
+	base := inBaseCurrency(car, rates)
+	score := 0.0
+	for _, attr := range similarityAttributes {
+		w := attr.weight(s.weights)
+		if w == 0 {
+			continue
+		}
+		sim := attr.score(ref, &base)
+		contribution := w * sim / total
+		score += contribution
+		similar.Factors = append(similar.Factors, SimilarityFactor{
+			Attribute:    attr.name,
+			Value:        values[attr.name],
+			Weight:       w,
+			Similarity:   roundTo(sim, 3),
+			Contribution: roundTo(contribution, 3),
+		})
+	}
+	similar.Score = roundTo(score, 3)
+
+	return similar
+}
+
+// floatParam is a placeholder typed as a float. Postgres would otherwise infer an integer
+// from an integer column or literal next to it, and the driver would truncate the value.
+const floatParam = "CAST(? AS double precision)"
+
+func textMatchSQL(column, value string) (string, []interface{}) {
+	return "(CASE WHEN LOWER(" + column + ") = LOWER(?) THEN 1.0 ELSE 0.0 END)", []interface{}{value}
+}
+
+func textMatch(a, b string) float64 {
+	if strings.EqualFold(a, b) {
+		return 1
+	}
+	return 0
+}
+
+// closenessSQL mirrors closeness: 1 at the reference value, falling linearly to 0 at the scale distance
+func closenessSQL(column string, ref, scale float64) (string, []interface{}) {
+	distance := "ABS(" + column + " - " + floatParam + ")"
+	return "(CASE WHEN " + distance + " >= " + floatParam + " THEN 0.0 ELSE 1.0 - " + distance + " / " + floatParam + " END)",
+		[]interface{}{ref, scale, ref, scale}
+}
+
+func closeness(v, ref, scale float64) float64 {
+	d := math.Abs(v - ref)
+	if d >= scale {
+		return 0
+	}
+	return 1 - d/scale
+}
+
+func priceScale(ref *models.Car) float64 {
+	return math.Max(ref.Price*similarPriceBand, 1)
+}
+
+// inBaseCurrency returns a copy of car with its price converted to the base currency,
+// so that prices in different currencies can be scored against each other
+func inBaseCurrency(car models.Car, rates map[string]float64) models.Car {
+	car.Price = toBaseCurrency(car.Price, car.Currency, rates)
+	car.Currency = models.BaseCurrency
+	return car
+}
+
+func roundTo(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
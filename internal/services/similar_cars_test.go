@@ -0,0 +1,85 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"testing"
+)
+
+// TestSimilarCarsOrderMatchesScores checks that the ranking done in SQL agrees with the scores explain reports.
+// Fractional weights and closeness values would be truncated if a placeholder were typed as an integer.
+func TestSimilarCarsOrderMatchesScores(t *testing.T) {
+	db := openTestDB(t, "cars")
+
+	car := func(year int, engine float64, transmission string, mileage float64) *models.Car {
+		c := &models.Car{
+			Brand: "Toyota", Model: "Camry", CarType: "suv", Year: year, Price: 20000,
+			EngineVolume: engine, Transmission: transmission, Mileage: mileage,
+		}
+		if err := db.Create(c).Error; err != nil {
+			t.Fatalf("create car: %v", err)
+		}
+		return c
+	}
+	ref := &models.Car{
+		Brand: "Toyota", Model: "Corolla", CarType: "sedan", Year: 2015, Price: 10000,
+		EngineVolume: 2.0, Transmission: "automatic", Mileage: 50000,
+	}
+	if err := db.Create(ref).Error; err != nil {
+		t.Fatalf("create car: %v", err)
+	}
+	engineOnly := car(2025, 2.0, "manual", 200000) // engine: 1
+	yearOnly := car(2015, 4.0, "manual", 200000)   // year: 1.5
+	nearYear := car(2013, 4.0, "manual", 50000)    // year: 1.5 × 0.6, mileage: 1
+
+	tests := []struct {
+		name    string
+		weights string
+		want    []uint
+	}{
+		{name: "default weights", want: []uint{nearYear.ID, yearOnly.ID, engineOnly.ID}},
+		{name: "fractional weights", weights: "year=0.5", want: []uint{nearYear.ID, engineOnly.ID, yearOnly.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weights, err := ParseSimilarityWeights(tt.weights)
+			if err != nil {
+				t.Fatal(err)
+			}
+			service, err := NewSimilarCarsService(db, weights)
+			if err != nil {
+				t.Fatal(err)
+			}
+			similar, err := service.GetSimilarCars(ref.ID, MaxSimilarCars)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(similar) != 3 {
+				t.Fatalf("got %d similar cars, want 3", len(similar))
+			}
+
+			var got []uint
+			for i, s := range similar {
+				got = append(got, s.Car.ID)
+				if i > 0 && s.Score > similar[i-1].Score {
+					t.Errorf("car %d scored %.3f is ranked below car %d scored %.3f",
+						s.Car.ID, s.Score, similar[i-1].Car.ID, similar[i-1].Score)
+				}
+			}
+			if tt.want != nil && !equalIDs(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -41,7 +41,7 @@ func (s *SuperAdminService) UpdateUserRole(userID uint, newRole string) error {
 	}
 
 	// Validate new role
-	if newRole != string(models.RoleUser) && newRole != string(models.RoleAdmin) {
+	if newRole != string(models.RoleUser) && newRole != string(models.RoleSeller) && newRole != string(models.RoleAdmin) {
 		return errors.New("invalid role")
 	}
 
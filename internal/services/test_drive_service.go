@@ -0,0 +1,471 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxTestDriveSlotDays limits how many days of slots can be requested at once
+const MaxTestDriveSlotDays = 31
+
+// Test drive errors
+var (
+	ErrTestDriveNotFound    = errors.New("test drive not found")
+	ErrTestDriveForbidden   = errors.New("not authorized to manage this test drive")
+	ErrTestDriveClosed      = errors.New("test drive is cancelled or already started")
+	ErrCarNotBookable       = errors.New("car is not available for test drives")
+	ErrSlotUnavailable      = errors.New("test drive slot is not available")
+	ErrInvalidSlotRange     = errors.New("invalid slot range")
+	ErrAvailabilityNotFound = errors.New("availability not found")
+	ErrAvailabilityOverlap  = errors.New("availability overlaps an existing one")
+	ErrBlackoutNotFound     = errors.New("blackout not found")
+)
+
+// TestDriveSlot is an open time window in which a test drive can be booked
+type TestDriveSlot struct {
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// TestDriveService manages availability templates, blackout dates and test drive bookings
+type TestDriveService struct {
+	db  *gorm.DB
+	loc *time.Location
+}
+
+// NewTestDriveService creates a new instance of TestDriveService.
+// Availability templates and blackout dates are interpreted in loc.
+func NewTestDriveService(db *gorm.DB, loc *time.Location) *TestDriveService {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return &TestDriveService{db: db, loc: loc}
+}
+
+// ListAvailability returns every availability template, global ones first
+func (s *TestDriveService) ListAvailability() ([]models.TestDriveAvailability, error) {
+	availability := []models.TestDriveAvailability{}
+	err := s.db.Order("car_id NULLS FIRST, weekday, start_time").Find(&availability).Error
+	return availability, err
+}
+
+// CreateAvailability adds a weekly availability template.
+// Templates of the same car (or of all cars) must not overlap on the same weekday.
+func (s *TestDriveService) CreateAvailability(availability *models.TestDriveAvailability) error {
+	availability.ID = 0
+	if err := availability.Validate(); err != nil {
+		return err
+	}
+	start, _ := models.TimeOfDay(availability.StartTime)
+	end, _ := models.TimeOfDay(availability.EndTime)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if availability.CarID != nil {
+			if err := tx.Select("id").First(&models.Car{}, *availability.CarID).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrCarNotFound
+				}
+				return err
+			}
+		}
+
+		var existing []models.TestDriveAvailability
+		if err := carScope(tx, availability.CarID).Where("weekday = ?", availability.Weekday).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, other := range existing {
+			otherStart, _ := models.TimeOfDay(other.StartTime)
+			otherEnd, _ := models.TimeOfDay(other.EndTime)
+			if start < otherEnd && otherStart < end {
+				return ErrAvailabilityOverlap
+			}
+		}
+
+		return tx.Create(availability).Error
+	})
+}
+
+// DeleteAvailability removes an availability template. Booked test drives are kept.
+func (s *TestDriveService) DeleteAvailability(id uint) error {
+	result := s.db.Delete(&models.TestDriveAvailability{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAvailabilityNotFound
+	}
+	return nil
+}
+
+// ListBlackouts returns the blackout dates from the given day on, or all of them when from is empty
+func (s *TestDriveService) ListBlackouts(from string) ([]models.TestDriveBlackout, error) {
+	query := s.db.Order("date, id")
+	if from != "" {
+		query = query.Where("date >= ?", from)
+	}
+	blackouts := []models.TestDriveBlackout{}
+	err := query.Find(&blackouts).Error
+	return blackouts, err
+}
+
+// CreateBlackout closes a day for test drives. Test drives already booked on that day are kept.
+func (s *TestDriveService) CreateBlackout(blackout *models.TestDriveBlackout) error {
+	blackout.ID = 0
+	if err := blackout.Validate(); err != nil {
+		return err
+	}
+	if blackout.CarID != nil {
+		if err := s.db.Select("id").First(&models.Car{}, *blackout.CarID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCarNotFound
+			}
+			return err
+		}
+	}
+	return s.db.Create(blackout).Error
+}
+
+// DeleteBlackout reopens a blackout date
+func (s *TestDriveService) DeleteBlackout(id uint) error {
+	result := s.db.Delete(&models.TestDriveBlackout{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrBlackoutNotFound
+	}
+	return nil
+}
+
+// SlotRange parses the from and to dates (YYYY-MM-DD, both inclusive) of a slot query.
+// from defaults to today and to to a week after from.
+func (s *TestDriveService) SlotRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	start := dayStart(now.In(s.loc))
+	if from != "" {
+		day, err := time.ParseInLocation("2006-01-02", from, s.loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidSlotRange)
+		}
+		start = day
+	}
+
+	end := start.AddDate(0, 0, 6)
+	if to != "" {
+		day, err := time.ParseInLocation("2006-01-02", to, s.loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidSlotRange)
+		}
+		end = day
+	}
+
+	if end.Before(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must not be before from", ErrInvalidSlotRange)
+	}
+	if end.After(start.AddDate(0, 0, MaxTestDriveSlotDays-1)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: at most %d days can be requested", ErrInvalidSlotRange, MaxTestDriveSlotDays)
+	}
+	return start, end, nil
+}
+
+// GetSlots returns the open test drive slots of a car between the from and to days (inclusive).
+// Slots in the past, on blackout dates or overlapping a booking are left out.
+func (s *TestDriveService) GetSlots(carID uint, from, to time.Time) ([]TestDriveSlot, error) {
+	var car models.Car
+	if err := s.db.Select("id", "status").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCarNotFound
+		}
+		return nil, err
+	}
+	if !testDriveAllowed(car.Status) {
+		return nil, ErrCarNotBookable
+	}
+	return s.openSlots(s.db, carID, from, to, 0, time.Now())
+}
+
+// BookTestDrive books the open slot starting at startsAt for the user.
+// The car row is locked while the slot is checked and booked, so two concurrent requests
+// cannot book overlapping slots; the partial unique index on booked slots backs this up.
+func (s *TestDriveService) BookTestDrive(userID, carID uint, startsAt time.Time, notes string) (*models.TestDrive, error) {
+	var testDrive models.TestDrive
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockBookableCar(tx, carID); err != nil {
+			return err
+		}
+		slot, err := s.findSlot(tx, carID, startsAt, 0)
+		if err != nil {
+			return err
+		}
+
+		testDrive = models.TestDrive{
+			CarID:    carID,
+			UserID:   userID,
+			StartsAt: slot.StartsAt,
+			EndsAt:   slot.EndsAt,
+			Status:   models.TestDriveBooked,
+			Notes:    notes,
+		}
+		return tx.Create(&testDrive).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTestDriveByID(testDrive.ID)
+}
+
+// RescheduleTestDrive moves an upcoming test drive to the open slot starting at startsAt.
+// Only the user who booked it or an admin may reschedule it.
+func (s *TestDriveService) RescheduleTestDrive(id, actorID uint, admin bool, startsAt time.Time) (*models.TestDrive, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var testDrive models.TestDrive
+		if err := tx.First(&testDrive, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTestDriveNotFound
+			}
+			return err
+		}
+
+		// Машину блокируем раньше записи, в том же порядке, что и при бронировании
+		if err := lockBookableCar(tx, testDrive.CarID); err != nil {
+			return err
+		}
+		if err := lockOpenTestDrive(tx, &testDrive, actorID, admin); err != nil {
+			return err
+		}
+
+		slot, err := s.findSlot(tx, testDrive.CarID, startsAt, testDrive.ID)
+		if err != nil {
+			return err
+		}
+		return tx.Model(&testDrive).Updates(map[string]interface{}{
+			"starts_at": slot.StartsAt,
+			"ends_at":   slot.EndsAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTestDriveByID(id)
+}
+
+// CancelTestDrive cancels an upcoming test drive and frees its slot.
+// Only the user who booked it or an admin may cancel it.
+func (s *TestDriveService) CancelTestDrive(id, actorID uint, admin bool) (*models.TestDrive, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		testDrive := models.TestDrive{ID: id}
+		if err := lockOpenTestDrive(tx, &testDrive, actorID, admin); err != nil {
+			return err
+		}
+		return tx.Model(&testDrive).Updates(map[string]interface{}{
+			"status":       models.TestDriveCancelled,
+			"cancelled_by": actorID,
+			"cancelled_at": time.Now(),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTestDriveByID(id)
+}
+
+// GetTestDriveByID retrieves a test drive by its ID
+func (s *TestDriveService) GetTestDriveByID(id uint) (*models.TestDrive, error) {
+	var testDrive models.TestDrive
+	if err := s.db.Preload("Car").First(&testDrive, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTestDriveNotFound
+		}
+		return nil, err
+	}
+	return &testDrive, nil
+}
+
+// GetUserTestDrives returns the test drives of a user, soonest first, optionally filtered by status
+func (s *TestDriveService) GetUserTestDrives(userID uint, status models.TestDriveStatus) ([]models.TestDrive, error) {
+	query := s.db.Preload("Car").Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	testDrives := []models.TestDrive{}
+	if err := query.Order("starts_at").Find(&testDrives).Error; err != nil {
+		return nil, err
+	}
+	return testDrives, nil
+}
+
+// ListTestDrives returns all test drives for admins, optionally filtered by status and car
+func (s *TestDriveService) ListTestDrives(status models.TestDriveStatus, carID uint) ([]models.TestDrive, error) {
+	query := s.db.Preload("Car")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if carID != 0 {
+		query = query.Where("car_id = ?", carID)
+	}
+
+	testDrives := []models.TestDrive{}
+	if err := query.Order("starts_at").Find(&testDrives).Error; err != nil {
+		return nil, err
+	}
+	return testDrives, nil
+}
+
+// findSlot returns the open slot of a car starting exactly at startsAt, ignoring the booking excludeID
+func (s *TestDriveService) findSlot(tx *gorm.DB, carID uint, startsAt time.Time, excludeID uint) (*TestDriveSlot, error) {
+	day := dayStart(startsAt.In(s.loc))
+	slots, err := s.openSlots(tx, carID, day, day, excludeID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		if slot.StartsAt.Equal(startsAt) {
+			return &slot, nil
+		}
+	}
+	return nil, ErrSlotUnavailable
+}
+
+// openSlots builds the slots of a car from its availability templates for every day from from to to,
+// and drops past slots, blackout dates and slots overlapping a booking other than excludeID
+func (s *TestDriveService) openSlots(db *gorm.DB, carID uint, from, to time.Time, excludeID uint, now time.Time) ([]TestDriveSlot, error) {
+	// Собственное расписание машины заменяет общее
+	var templates []models.TestDriveAvailability
+	if err := db.Where("car_id = ?", carID).Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	if len(templates) == 0 {
+		if err := db.Where("car_id IS NULL").Find(&templates).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var blackoutDates []string
+	err := db.Model(&models.TestDriveBlackout{}).
+		Where("(car_id IS NULL OR car_id = ?) AND date BETWEEN ? AND ?", carID, from.Format("2006-01-02"), to.Format("2006-01-02")).
+		Pluck("date", &blackoutDates).Error
+	if err != nil {
+		return nil, err
+	}
+	closed := make(map[string]bool, len(blackoutDates))
+	for _, date := range blackoutDates {
+		closed[date] = true
+	}
+
+	var booked []models.TestDrive
+	err = db.Where("car_id = ? AND status = ? AND id <> ? AND starts_at < ? AND ends_at > ?",
+		carID, models.TestDriveBooked, excludeID, to.AddDate(0, 0, 1), from).
+		Find(&booked).Error
+	if err != nil {
+		return nil, err
+	}
+
+	slots := []TestDriveSlot{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		if closed[day.Format("2006-01-02")] {
+			continue
+		}
+		for _, template := range templates {
+			if time.Weekday(template.Weekday) != day.Weekday() {
+				continue
+			}
+			start, err := models.TimeOfDay(template.StartTime)
+			if err != nil {
+				return nil, err
+			}
+			end, err := models.TimeOfDay(template.EndTime)
+			if err != nil {
+				return nil, err
+			}
+			for minute := start; minute+template.SlotMinutes <= end; minute += template.SlotMinutes {
+				slot := TestDriveSlot{
+					StartsAt: time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, s.loc),
+				}
+				slot.EndsAt = slot.StartsAt.Add(time.Duration(template.SlotMinutes) * time.Minute)
+				if !slot.StartsAt.After(now) || overlapsBooking(slot, booked) {
+					continue
+				}
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	sort.Slice(slots, func(i, j int) bool { return slots[i].StartsAt.Before(slots[j].StartsAt) })
+	return slots, nil
+}
+
+func overlapsBooking(slot TestDriveSlot, booked []models.TestDrive) bool {
+	for _, testDrive := range booked {
+		if slot.StartsAt.Before(testDrive.EndsAt) && testDrive.StartsAt.Before(slot.EndsAt) {
+			return true
+		}
+	}
+	return false
+}
+
+// lockBookableCar locks the car row and checks that it can be test-driven
+func lockBookableCar(tx *gorm.DB, carID uint) error {
+	var car models.Car
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&car, carID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCarNotFound
+		}
+		return err
+	}
+	if !testDriveAllowed(car.Status) {
+		return ErrCarNotBookable
+	}
+	return nil
+}
+
+// lockOpenTestDrive locks a test drive and checks that the actor may change it and that it is still upcoming
+func lockOpenTestDrive(tx *gorm.DB, testDrive *models.TestDrive, actorID uint, admin bool) error {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(testDrive, testDrive.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTestDriveNotFound
+		}
+		return err
+	}
+	if !admin && testDrive.UserID != actorID {
+		return ErrTestDriveForbidden
+	}
+	if testDrive.Status != models.TestDriveBooked || !testDrive.StartsAt.After(time.Now()) {
+		return ErrTestDriveClosed
+	}
+	return nil
+}
+
+// cancelCarTestDrivesTx cancels the upcoming test drives of a car that is taken off the market
+func cancelCarTestDrivesTx(tx *gorm.DB, carID uint, actorID *uint) error {
+	now := time.Now()
+	return tx.Model(&models.TestDrive{}).
+		Where("car_id = ? AND status = ? AND starts_at > ?", carID, models.TestDriveBooked, now).
+		Updates(map[string]interface{}{
+			"status":       models.TestDriveCancelled,
+			"cancelled_by": actorID,
+			"cancelled_at": now,
+		}).Error
+}
+
+// testDriveAllowed reports whether cars with the given status can be test-driven
+func testDriveAllowed(status models.CarStatus) bool {
+	return status != models.StatusSold && status != models.StatusMaintenance
+}
+
+// carScope restricts a query on availability templates to one car, or to the templates of all cars
+func carScope(db *gorm.DB, carID *uint) *gorm.DB {
+	if carID == nil {
+		return db.Where("car_id IS NULL")
+	}
+	return db.Where("car_id = ?", *carID)
+}
+
+func dayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
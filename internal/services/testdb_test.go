@@ -0,0 +1,41 @@
+package services
+
+import (
+	"Cars/internal/migrations"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the disposable Postgres database named by TEST_DATABASE_URL, applies the migrations
+// and empties the given tables. It also points DB at the database for the package-level functions.
+// Tests that need a database are skipped when the variable is not set.
+func openTestDB(t *testing.T, tables ...string) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := migrations.GetMigrations(db).Migrate(); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if len(tables) > 0 {
+		if err := db.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+	}
+
+	previous := DB
+	DB = db
+	t.Cleanup(func() { DB = previous })
+	return db
+}
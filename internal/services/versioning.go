@@ -0,0 +1,32 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionMismatch is returned when the If-Match precondition of a write does not match the stored version
+var ErrVersionMismatch = errors.New("resource was modified by someone else; reload it and try again")
+
+// VersionMatch lists the versions a conditional write accepts; an empty list accepts any version
+type VersionMatch []int
+
+// Allows reports whether a write may proceed against the stored version
+func (m VersionMatch) Allows(version int) bool {
+	if len(m) == 0 {
+		return true
+	}
+	for _, v := range m {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// bumpCarVersionTx increments the version of a car after an edit
+func bumpCarVersionTx(tx *gorm.DB, carID uint) error {
+	return tx.Model(&models.Car{}).Where("id = ?", carID).UpdateColumn("version", gorm.Expr("version + 1")).Error
+}
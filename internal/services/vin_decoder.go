@@ -0,0 +1,335 @@
+package services
+
+import (
+	"Cars/internal/models"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// VIN errors
+var (
+	ErrVINMismatch  = errors.New("car data does not match the VIN")
+	ErrDuplicateVIN = errors.New("a car with this VIN is already listed")
+)
+
+// vinYearCodes are the model year codes of position 10; the sequence starts at 1980 and repeats every 30 years
+const vinYearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+// vinManufacturer is an entry of the bundled WMI table
+type vinManufacturer struct {
+	Manufacturer string
+	Brand        string // name as used in the brand catalog
+}
+
+// vinManufacturers maps world manufacturer identifiers (the first three VIN characters) to manufacturers.
+// Two-character keys cover manufacturers that use every third character.
+var vinManufacturers = map[string]vinManufacturer{
+	"JT":  {"Toyota Motor Corporation", "Toyota"},
+	"4T1": {"Toyota Motor Manufacturing Kentucky", "Toyota"},
+	"4T3": {"Toyota Motor Manufacturing Kentucky", "Toyota"},
+	"5TD": {"Toyota Motor Manufacturing Indiana", "Toyota"},
+	"2T1": {"Toyota Motor Manufacturing Canada", "Toyota"},
+	"NMT": {"Toyota Motor Manufacturing Turkey", "Toyota"},
+	"SB1": {"Toyota Motor Manufacturing UK", "Toyota"},
+	"JTH": {"Toyota Motor Corporation", "Lexus"},
+	"2T2": {"Toyota Motor Manufacturing Canada", "Lexus"},
+	"JHM": {"Honda Motor Co.", "Honda"},
+	"1HG": {"Honda of America Mfg.", "Honda"},
+	"2HG": {"Honda of Canada Mfg.", "Honda"},
+	"JN1": {"Nissan Motor Co.", "Nissan"},
+	"1N4": {"Nissan North America", "Nissan"},
+	"SJN": {"Nissan Motor Manufacturing UK", "Nissan"},
+	"JM1": {"Mazda Motor Corporation", "Mazda"},
+	"JF1": {"Subaru Corporation", "Subaru"},
+	"4S3": {"Subaru of Indiana Automotive", "Subaru"},
+	"JA3": {"Mitsubishi Motors", "Mitsubishi"},
+	"JS1": {"Suzuki Motor Corporation", "Suzuki"},
+	"KMH": {"Hyundai Motor Company", "Hyundai"},
+	"5NP": {"Hyundai Motor Manufacturing Alabama", "Hyundai"},
+	"TMA": {"Hyundai Motor Manufacturing Czech", "Hyundai"},
+	"KNA": {"Kia Corporation", "Kia"},
+	"KND": {"Kia Corporation", "Kia"},
+	"U5Y": {"Kia Slovakia", "Kia"},
+	"XWE": {"Avtotor", "Kia"},
+	"WBA": {"BMW AG", "BMW"},
+	"WBS": {"BMW M GmbH", "BMW"},
+	"5UX": {"BMW Manufacturing Co.", "BMW"},
+	"WMW": {"BMW AG", "MINI"},
+	"WDB": {"Mercedes-Benz AG", "Mercedes-Benz"},
+	"WDD": {"Mercedes-Benz AG", "Mercedes-Benz"},
+	"W1K": {"Mercedes-Benz AG", "Mercedes-Benz"},
+	"4JG": {"Mercedes-Benz U.S. International", "Mercedes-Benz"},
+	"WAU": {"Audi AG", "Audi"},
+	"WA1": {"Audi AG", "Audi"},
+	"TRU": {"Audi Hungaria", "Audi"},
+	"WVW": {"Volkswagen AG", "Volkswagen"},
+	"WV1": {"Volkswagen Commercial Vehicles", "Volkswagen"},
+	"WV2": {"Volkswagen Commercial Vehicles", "Volkswagen"},
+	"3VW": {"Volkswagen de Mexico", "Volkswagen"},
+	"1VW": {"Volkswagen Chattanooga", "Volkswagen"},
+	"XW8": {"Volkswagen Group Rus", "Volkswagen"},
+	"TMB": {"Skoda Auto", "Skoda"},
+	"VSS": {"SEAT", "SEAT"},
+	"WP0": {"Porsche AG", "Porsche"},
+	"WP1": {"Porsche AG", "Porsche"},
+	"VF1": {"Renault", "Renault"},
+	"X7L": {"Renault Russia", "Renault"},
+	"VF3": {"Peugeot", "Peugeot"},
+	"VF7": {"Citroen", "Citroen"},
+	"ZFA": {"Fiat", "Fiat"},
+	"ZAR": {"Alfa Romeo", "Alfa Romeo"},
+	"ZFF": {"Ferrari", "Ferrari"},
+	"YV1": {"Volvo Cars", "Volvo"},
+	"SAL": {"Jaguar Land Rover", "Land Rover"},
+	"SAJ": {"Jaguar Land Rover", "Jaguar"},
+	"1FA": {"Ford Motor Company", "Ford"},
+	"1FT": {"Ford Motor Company", "Ford"},
+	"1FM": {"Ford Motor Company", "Ford"},
+	"WF0": {"Ford Germany", "Ford"},
+	"1G1": {"General Motors", "Chevrolet"},
+	"1GC": {"General Motors", "Chevrolet"},
+	"KL1": {"GM Korea", "Chevrolet"},
+	"XUU": {"Avtotor", "Chevrolet"},
+	"1GY": {"General Motors", "Cadillac"},
+	"W0L": {"Opel", "Opel"},
+	"1C4": {"Chrysler", "Jeep"},
+	"1J4": {"Chrysler", "Jeep"},
+	"2C3": {"Chrysler Canada", "Chrysler"},
+	"5YJ": {"Tesla", "Tesla"},
+	"7SA": {"Tesla", "Tesla"},
+	"LRW": {"Tesla Shanghai", "Tesla"},
+	"XTA": {"AvtoVAZ", "Lada"},
+	"XTT": {"UAZ", "UAZ"},
+	"X96": {"GAZ", "GAZ"},
+	"LVS": {"Changan Ford", "Ford"},
+	"LGX": {"BYD Auto", "BYD"},
+	"LB3": {"Geely", "Geely"},
+	"LVV": {"Chery", "Chery"},
+	"LGW": {"Great Wall Motor", "Haval"},
+	"Y6D": {"ZAZ", "ZAZ"},
+	"Z94": {"Hyundai Motor Manufacturing Rus", "Hyundai"},
+	"Z8N": {"Nissan Manufacturing Rus", "Nissan"},
+	"XW7": {"Toyota Motor Manufacturing Russia", "Toyota"},
+}
+
+// vinCountry maps a range of the first two VIN characters to a country
+type vinCountry struct {
+	from, to string
+	country  string
+}
+
+var vinCountries = []vinCountry{
+	{"1A", "19", "United States"}, {"4A", "49", "United States"}, {"5A", "59", "United States"},
+	{"2A", "29", "Canada"}, {"3A", "37", "Mexico"},
+	{"JA", "J9", "Japan"}, {"KL", "KR", "South Korea"}, {"LA", "L9", "China"},
+	{"MA", "ME", "India"}, {"NL", "NR", "Turkey"},
+	{"SA", "SM", "United Kingdom"}, {"SN", "ST", "Germany"}, {"TA", "TH", "Switzerland"},
+	{"TJ", "TP", "Czech Republic"}, {"TR", "TV", "Hungary"}, {"U5", "U7", "Slovakia"},
+	{"VA", "VE", "Austria"}, {"VF", "VR", "France"}, {"VS", "VW", "Spain"},
+	{"WA", "W9", "Germany"}, {"X3", "X0", "Russia"}, {"XS", "XW", "Russia"}, {"YA", "YE", "Belgium"},
+	{"YS", "YW", "Sweden"}, {"ZA", "ZR", "Italy"}, {"Y6", "Y0", "Ukraine"}, {"Z6", "Z0", "Russia"},
+}
+
+// vinCharOrder is the order of VIN characters used for the country ranges
+const vinCharOrder = "ABCDEFGHJKLMNPRSTUVWXYZ1234567890"
+
+// VINInfo is the result of decoding a VIN
+type VINInfo struct {
+	VIN                 string `json:"vin"`
+	CheckDigitValid     bool   `json:"check_digit_valid"`
+	WMI                 string `json:"wmi"`
+	VDS                 string `json:"vds"`
+	VIS                 string `json:"vis"`
+	Region              string `json:"region"`
+	Country             string `json:"country,omitempty"`
+	Manufacturer        string `json:"manufacturer,omitempty"`
+	Brand               string `json:"brand,omitempty"`
+	ModelYear           int    `json:"model_year,omitempty"`
+	ModelYearCandidates []int  `json:"model_year_candidates,omitempty"`
+	PlantCode           string `json:"plant_code"`
+	SerialNumber        string `json:"serial_number"`
+}
+
+// NormalizeVIN upper-cases a VIN and removes surrounding spaces
+func NormalizeVIN(vin string) string {
+	return strings.ToUpper(strings.TrimSpace(vin))
+}
+
+// DecodeVIN decodes the manufacturer, country and model year of a VIN using the bundled tables.
+// A wrong check digit does not stop decoding; it is reported in CheckDigitValid.
+func DecodeVIN(vin string) (*VINInfo, error) {
+	vin = NormalizeVIN(vin)
+	if !models.ValidVINFormat(vin) {
+		return nil, models.ErrInvalidVIN
+	}
+
+	info := &VINInfo{
+		VIN:             vin,
+		CheckDigitValid: models.VINCheckDigit(vin) == vin[8],
+		WMI:             vin[:3],
+		VDS:             vin[3:9],
+		VIS:             vin[9:],
+		Region:          vinRegion(vin[0]),
+		Country:         vinCountryOf(vin[:2]),
+		PlantCode:       vin[10:11],
+		SerialNumber:    vin[11:],
+	}
+
+	if m, ok := vinManufacturers[vin[:3]]; ok {
+		info.Manufacturer, info.Brand = m.Manufacturer, m.Brand
+	} else if m, ok := vinManufacturers[vin[:2]]; ok {
+		info.Manufacturer, info.Brand = m.Manufacturer, m.Brand
+	}
+
+	info.ModelYear, info.ModelYearCandidates = vinModelYear(vin)
+	return info, nil
+}
+
+// MatchesBrand reports whether brand names the manufacturer of the VIN.
+// Punctuation and spaces are ignored and a shorter catalog name such as "Mercedes" matches "Mercedes-Benz".
+func (v *VINInfo) MatchesBrand(brand string) bool {
+	if v.Brand == "" {
+		return true
+	}
+	a, b := brandKey(v.Brand), brandKey(brand)
+	if a == "" || b == "" {
+		return false
+	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
+
+// MatchesYear reports whether year is one of the possible model years of the VIN
+func (v *VINInfo) MatchesYear(year int) bool {
+	if len(v.ModelYearCandidates) == 0 {
+		return true
+	}
+	for _, candidate := range v.ModelYearCandidates {
+		if candidate == year {
+			return true
+		}
+	}
+	return false
+}
+
+// prefillFromVIN fills an empty brand and year of a new car from its VIN.
+// Invalid VINs are left for Car.Validate to report.
+func prefillFromVIN(car *models.Car) {
+	if car.VIN == "" {
+		return
+	}
+	info, err := DecodeVIN(car.VIN)
+	if err != nil {
+		return
+	}
+	if car.Brand == "" && car.BrandID == nil && info.Brand != "" {
+		car.Brand = info.Brand
+	}
+	if car.Year == 0 && info.ModelYear != 0 {
+		car.Year = info.ModelYear
+	}
+}
+
+// checkCarVIN rejects a car whose brand or year contradicts its VIN or whose VIN is already
+// listed by another car. It runs after the references are resolved so the canonical brand is compared.
+func checkCarVIN(db *gorm.DB, car *models.Car) error {
+	if car.VIN == "" {
+		return nil
+	}
+	info, err := DecodeVIN(car.VIN)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCar, err)
+	}
+	if !info.MatchesBrand(car.Brand) {
+		return fmt.Errorf("%w: VIN belongs to %s, not %s", ErrVINMismatch, info.Brand, car.Brand)
+	}
+	if !info.MatchesYear(car.Year) {
+		return fmt.Errorf("%w: VIN model year is %d, not %d", ErrVINMismatch, info.ModelYear, car.Year)
+	}
+
+	return ensureUniqueVIN(db, car.VIN, car.ID)
+}
+
+// ensureUniqueVIN returns ErrDuplicateVIN when a car other than carID already has the VIN
+func ensureUniqueVIN(db *gorm.DB, vin string, carID uint) error {
+	var count int64
+	if err := db.Model(&models.Car{}).Where("vin = ? AND id <> ?", vin, carID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDuplicateVIN
+	}
+	return nil
+}
+
+func brandKey(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// vinModelYear returns the most likely model year and every possible one for the year code in position 10.
+// The code repeats every 30 years; for North American VINs a letter in position 7 means 2010 or later.
+func vinModelYear(vin string) (int, []int) {
+	idx := strings.IndexByte(vinYearCodes, vin[9])
+	if idx < 0 {
+		return 0, nil
+	}
+
+	maxYear := time.Now().Year() + 1
+	var candidates []int
+	for year := 1980 + idx; year <= maxYear; year += 30 {
+		candidates = append(candidates, year)
+	}
+	if len(candidates) == 0 {
+		return 0, nil
+	}
+
+	if vin[0] >= '1' && vin[0] <= '5' && len(candidates) > 1 {
+		if vin[6] >= 'A' && vin[6] <= 'Z' {
+			return candidates[1], candidates
+		}
+		return candidates[0], candidates
+	}
+	return candidates[len(candidates)-1], candidates
+}
+
+func vinRegion(c byte) string {
+	switch {
+	case c >= '1' && c <= '5':
+		return "North America"
+	case c == '6' || c == '7':
+		return "Oceania"
+	case c == '8' || c == '9' || c == '0':
+		return "South America"
+	case c >= 'A' && c <= 'H':
+		return "Africa"
+	case c >= 'J' && c <= 'R':
+		return "Asia"
+	default:
+		return "Europe"
+	}
+}
+
+func vinCountryOf(prefix string) string {
+	pos := strings.IndexByte(vinCharOrder, prefix[1])
+	for _, c := range vinCountries {
+		if prefix[0] != c.from[0] {
+			continue
+		}
+		from := strings.IndexByte(vinCharOrder, c.from[1])
+		to := strings.IndexByte(vinCharOrder, c.to[1])
+		if pos >= from && pos <= to {
+			return c.country
+		}
+	}
+	return ""
+}
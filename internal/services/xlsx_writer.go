@@ -0,0 +1,109 @@
+package services
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+// xlsxWriter streams a single-sheet XLSX workbook.
+// Rows are written straight into the zip entry, so memory use does not grow with the row count.
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	err   error
+}
+
+// xlsxStaticParts are the fixed workbook parts around the streamed worksheet
+var xlsxStaticParts = []struct {
+	name string
+	body string
+}{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/><Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Cars" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/></Relationships>`},
+	{"xl/styles.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><fonts count="1"><font><sz val="11"/><name val="Calibri"/></font></fonts><fills count="1"><fill><patternFill patternType="none"/></fill></fills><borders count="1"><border/></borders><cellStyleXfs count="1"><xf/></cellStyleXfs><cellXfs count="1"><xf/></cellXfs></styleSheet>`},
+}
+
+// newXLSXWriter writes the static workbook parts and opens the worksheet for streaming
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	x := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f)}
+	x.writeString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n" +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	return x, x.err
+}
+
+// WriteRow appends a row; strings, bools and numeric types are written with their native cell types.
+// Strings are always inline strings, so text such as "=SUM(A1)" is shown as is and never evaluated as a formula.
+func (x *xlsxWriter) WriteRow(values ...interface{}) error {
+	x.writeString("<row>")
+	for _, v := range values {
+		switch val := v.(type) {
+		case string:
+			x.writeString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if x.err == nil {
+				x.err = xml.EscapeText(x.sheet, []byte(val))
+			}
+			x.writeString(`</t></is></c>`)
+		case bool:
+			if val {
+				x.writeString(`<c t="b"><v>1</v></c>`)
+			} else {
+				x.writeString(`<c t="b"><v>0</v></c>`)
+			}
+		case int:
+			x.writeString(`<c><v>` + strconv.Itoa(val) + `</v></c>`)
+		case uint:
+			x.writeString(`<c><v>` + strconv.FormatUint(uint64(val), 10) + `</v></c>`)
+		case float32:
+			x.writeString(`<c><v>` + strconv.FormatFloat(float64(val), 'f', -1, 32) + `</v></c>`)
+		case float64:
+			x.writeString(`<c><v>` + strconv.FormatFloat(val, 'f', -1, 64) + `</v></c>`)
+		default:
+			x.writeString(`<c/>`)
+		}
+	}
+	x.writeString("</row>")
+	return x.err
+}
+
+// Close finishes the worksheet and the zip archive
+func (x *xlsxWriter) Close() error {
+	x.writeString("</sheetData></worksheet>")
+	if x.err == nil {
+		x.err = x.sheet.Flush()
+	}
+	if x.err != nil {
+		return x.err
+	}
+	return x.zw.Close()
+}
+
+func (x *xlsxWriter) writeString(s string) {
+	if x.err == nil {
+		_, x.err = x.sheet.WriteString(s)
+	}
+}